@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+// Bot is the reference game.BotPlayer: it remembers what it has learned and votes by its Strategy.
+//
+// Use a new Bot for every seat:
+//
+//	lobby.JoinBot(player.NewNonPlayingPlayer("bot:1", "bot:1", "bot:1"), bot.New(bot.SensibleStrategy{}))
+
+type Option func(b *Bot)
+
+// SeedOpt sets the seed of the rand of the bot. Default - the time of the bot creation.
+func SeedOpt(seed int64) Option {
+	return func(b *Bot) { b.rand = rand.New(rand.NewSource(seed)) }
+}
+
+type Bot struct {
+	sync.Mutex
+	strategy  Strategy
+	rand      *rand.Rand
+	g         game.BotGame
+	knowledge Knowledge
+}
+
+func New(strategy Strategy, opts ...Option) *Bot {
+	b := &Bot{
+		strategy: strategy,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Knowledge presents what the bot knows about the game.
+type Knowledge struct {
+	ID        player.IDType
+	Role      *roles.Role
+	Teammates []player.IDType
+	// Roles are the roles of the checked players (Don).
+	Roles map[player.IDType]*roles.Role
+	// Suspicion of the players: the players, checked by the Detective in the different teams, are suspicious.
+	Suspicion map[player.IDType]int
+	// Checked players (by Don or Detective), they are not checked again.
+	Checked map[player.IDType]bool
+	// DayVotes are the votes of the current day voting, cast before the bot.
+	// Key - voter ID, value - vote. The reference bot does not see them, they are set by the simulator.
+	DayVotes map[player.IDType]player.IDType
+}
+
+// IsTeammate reports whether the player is the bot or his teammate.
+func (k *Knowledge) IsTeammate(id player.IDType) bool {
+	if id == k.ID {
+		return true
+	}
+	for _, teammate := range k.Teammates {
+		if teammate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// GetKnowledge returns the copy of the knowledge of the bot.
+func (b *Bot) GetKnowledge() Knowledge {
+	b.Lock()
+	defer b.Unlock()
+	k := b.knowledge
+	k.Roles = copyMap(k.Roles)
+	k.Suspicion = copyMap(k.Suspicion)
+	k.Checked = copyMap(k.Checked)
+	k.DayVotes = copyMap(k.DayVotes)
+	return k
+}
+
+func copyMap[V any](mp map[player.IDType]V) map[player.IDType]V {
+	copied := make(map[player.IDType]V, len(mp))
+	for k, v := range mp {
+		copied[k] = v
+	}
+	return copied
+}
+
+// ____________________
+// game.BotPlayer
+// ____________________
+
+func (b *Bot) Init(g game.BotGame, p player.Player, teammates []player.IDType) {
+	b.Lock()
+	defer b.Unlock()
+	b.g = g
+	b.knowledge = Knowledge{
+		ID:        p.ID,
+		Role:      p.Role,
+		Teammates: teammates,
+		Roles:     make(map[player.IDType]*roles.Role),
+		Suspicion: make(map[player.IDType]int),
+		Checked:   make(map[player.IDType]bool),
+	}
+}
+
+func (b *Bot) NightVote(info game.BotNightInfo) {
+	b.Lock()
+	targets := b.strategy.NightTargets(&b.knowledge, info, b.rand)
+	voterID := strconv.Itoa(int(b.knowledge.ID))
+	g := b.g
+	b.Unlock()
+
+	if info.Role.IsTwoVotes {
+		for i := 0; i+1 < len(targets); i += 2 {
+			vote1, vote2 := strconv.Itoa(int(targets[i])), strconv.Itoa(int(targets[i+1]))
+			if g.SetNightTwoVote(game.NewTwoVoteProvider(voterID, vote1, vote2, false, false)) == nil {
+				return
+			}
+		}
+		return
+	}
+	for _, target := range targets {
+		if g.SetNightVote(game.NewVoteProvider(voterID, strconv.Itoa(int(target)), false, false)) == nil {
+			return
+		}
+	}
+	// No accepted targets, the game will vote for the bot after the deadline.
+}
+
+func (b *Bot) NightResult(result game.BotNightResult) {
+	b.Lock()
+	defer b.Unlock()
+	k := &b.knowledge
+	for _, target := range result.Targets {
+		k.Checked[target] = true
+	}
+	if result.Role != nil && len(result.Targets) == 1 {
+		k.Roles[result.Targets[0]] = result.Role
+	}
+	if len(result.Targets) == 2 && !result.IsSameTeam {
+		for _, target := range result.Targets {
+			k.Suspicion[target]++
+		}
+	}
+}
+
+// DayMessage the reference bot does not read the messages, everything is in the night results.
+func (b *Bot) DayMessage(_ string) {}
+
+func (b *Bot) DayVote(info game.BotDayInfo) {
+	b.Lock()
+	target := b.strategy.DayTarget(&b.knowledge, info, b.rand)
+	voterID := strconv.Itoa(int(b.knowledge.ID))
+	g := b.g
+	b.Unlock()
+
+	if g.SetDayVote(game.NewVoteProvider(voterID, strconv.Itoa(int(target)), false, false)) != nil &&
+		target != game.EmptyVoteInt {
+		_ = g.SetDayVote(game.NewVoteProvider(voterID, game.EmptyVoteStr, false, false))
+	}
+}
@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+)
+
+// Strategy decides, how the bot votes.
+//
+// It is called under the lock of the bot, so it may keep everything in Knowledge.
+// The strategies are used by the simulator too, so they must not keep the state of the game in themselves.
+type Strategy interface {
+	// NightTargets returns the targets of the night vote in order of preference.
+	// The first target, accepted by the game, is used.
+	// For the roles with IsTwoVotes the targets are taken in pairs.
+	NightTargets(k *Knowledge, info game.BotNightInfo, rnd *rand.Rand) []player.IDType
+	// DayTarget returns the player, for whom the bot votes at the day, or game.EmptyVoteInt.
+	DayTarget(k *Knowledge, info game.BotDayInfo, rnd *rand.Rand) player.IDType
+}
+
+// others returns the shuffled ids except the bot and his teammates.
+func others(k *Knowledge, ids []player.IDType, rnd *rand.Rand) []player.IDType {
+	var targets []player.IDType
+	for _, id := range ids {
+		if !k.IsTeammate(id) {
+			targets = append(targets, id)
+		}
+	}
+	rnd.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+	return targets
+}
+
+// dayTargets returns the shuffled candidates (or active players) except the bot and his teammates.
+func dayTargets(k *Knowledge, info game.BotDayInfo, rnd *rand.Rand) []player.IDType {
+	if info.Candidates != nil {
+		return others(k, info.Candidates, rnd)
+	}
+	return others(k, info.Active, rnd)
+}
+
+// leader returns the player with the most votes in DayVotes among targets, or game.EmptyVoteInt.
+// Tie means the lowest ID.
+func leader(k *Knowledge, targets []player.IDType) player.IDType {
+	occurrences := make(map[player.IDType]int)
+	for _, vote := range k.DayVotes {
+		occurrences[vote]++
+	}
+	var (
+		result       player.IDType = game.EmptyVoteInt
+		mxOccurrence int
+	)
+	for _, target := range targets {
+		occurrence := occurrences[target]
+		if occurrence > mxOccurrence || (occurrence == mxOccurrence && occurrence > 0 && target < result) {
+			result, mxOccurrence = target, occurrence
+		}
+	}
+	return result
+}
+
+// teamRand returns the rand, which is the same for the bot and his teammates at the moment,
+// so they make the same choice without any communication.
+func teamRand(k *Knowledge, moment int) *rand.Rand {
+	team := append([]player.IDType{k.ID}, k.Teammates...)
+	slices.Sort(team)
+	team = slices.Compact(team)
+	seed := int64(moment) * 7919
+	for _, id := range team {
+		seed = seed*31 + int64(id)
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// pairs returns the targets by pairs for the roles with IsTwoVotes (every target with the next one).
+func pairs(targets []player.IDType) []player.IDType {
+	if len(targets) < 2 {
+		return nil
+	}
+	result := make([]player.IDType, 0, 2*len(targets))
+	for i := range targets {
+		result = append(result, targets[i], targets[(i+1)%len(targets)])
+	}
+	return result
+}
+
+// ____________
+// Strategies
+// ____________
+
+// RandomStrategy votes for the random players (except the teammates) at night and at the day.
+type RandomStrategy struct{}
+
+func (RandomStrategy) NightTargets(k *Knowledge, info game.BotNightInfo, rnd *rand.Rand) []player.IDType {
+	targets := others(k, info.Active, rnd)
+	if info.Role.IsTwoVotes {
+		return pairs(targets)
+	}
+	return targets
+}
+
+func (RandomStrategy) DayTarget(k *Knowledge, info game.BotDayInfo, rnd *rand.Rand) player.IDType {
+	targets := dayTargets(k, info, rnd)
+	if len(targets) == 0 {
+		return game.EmptyVoteInt
+	}
+	return targets[0]
+}
+
+// SensibleStrategy uses the night checks:
+// at night it checks the players, who are not checked yet,
+// at the day it votes for the known enemy (the checked player from the other team),
+// or for the most suspicious player (see Knowledge Suspicion), otherwise for the random one.
+type SensibleStrategy struct{}
+
+func (SensibleStrategy) NightTargets(k *Knowledge, info game.BotNightInfo, rnd *rand.Rand) []player.IDType {
+	targets := others(k, info.Active, rnd)
+	// Not checked first.
+	sort.SliceStable(targets, func(i, j int) bool { return !k.Checked[targets[i]] && k.Checked[targets[j]] })
+	if info.Role.IsTwoVotes {
+		return pairs(targets)
+	}
+	return targets
+}
+
+func (SensibleStrategy) DayTarget(k *Knowledge, info game.BotDayInfo, rnd *rand.Rand) player.IDType {
+	targets := dayTargets(k, info, rnd)
+	if len(targets) == 0 {
+		return game.EmptyVoteInt
+	}
+	score := func(id player.IDType) int {
+		if role, ok := k.Roles[id]; ok {
+			if role.Team == k.Role.Team {
+				return -1
+			}
+			return 100
+		}
+		return k.Suspicion[id]
+	}
+	sort.SliceStable(targets, func(i, j int) bool { return score(targets[i]) > score(targets[j]) })
+	return targets[0]
+}
+
+// TownStrategy plays for the town without any information:
+// random at night, but at the day it joins the leader of the voting,
+// so the town does not split the votes and kicks someone every day.
+type TownStrategy struct{}
+
+func (TownStrategy) NightTargets(k *Knowledge, info game.BotNightInfo, rnd *rand.Rand) []player.IDType {
+	return RandomStrategy{}.NightTargets(k, info, rnd)
+}
+
+func (TownStrategy) DayTarget(k *Knowledge, info game.BotDayInfo, rnd *rand.Rand) player.IDType {
+	targets := dayTargets(k, info, rnd)
+	if target := leader(k, targets); target != game.EmptyVoteInt {
+		return target
+	}
+	return RandomStrategy{}.DayTarget(k, info, rnd)
+}
+
+// MafiaStrategy is the coordinated mafia: all teammates choose the same target at night and at the day
+// (see teamRand), and join the leader of the day voting, if he is not a teammate.
+type MafiaStrategy struct{}
+
+func (MafiaStrategy) NightTargets(k *Knowledge, info game.BotNightInfo, _ *rand.Rand) []player.IDType {
+	targets := others(k, sorted(info.Active), teamRand(k, 2*info.Night))
+	if info.Role.IsTwoVotes {
+		return pairs(targets)
+	}
+	return targets
+}
+
+func (MafiaStrategy) DayTarget(k *Knowledge, info game.BotDayInfo, _ *rand.Rand) player.IDType {
+	candidates := info.Active
+	if info.Candidates != nil {
+		candidates = info.Candidates
+	}
+	targets := others(k, sorted(candidates), teamRand(k, 2*info.Day+1))
+	if len(targets) == 0 {
+		return game.EmptyVoteInt
+	}
+	if target := leader(k, targets); target != game.EmptyVoteInt {
+		return target
+	}
+	return targets[0]
+}
+
+// sorted returns the sorted copy of ids, so the teammates shuffle them in the same way.
+func sorted(ids []player.IDType) []player.IDType {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return ids
+}
@@ -77,3 +77,34 @@ func FromSpectatorToUser(channel Channel, serverUserID string) error {
 	}
 	return channel.AddPlayer(serverUserID)
 }
+
+// SpeakersChannel optional extension of Channel.
+//
+// Implement it, if you want that only the dying players may speak during their last words.
+// (See game LastWordsState)
+type SpeakersChannel interface {
+	Channel
+	// AllowOnlySpeakers leaves the right to write only to the users with these serverUserIDs.
+	// Others can only view.
+	AllowOnlySpeakers(serverUserIDs ...string) error
+	// AllowAllSpeakers returns the right to write to all players.
+	AllowAllSpeakers() error
+}
+
+// AllowOnlySpeakers calls AllowOnlySpeakers, if the channel implements SpeakersChannel.
+func AllowOnlySpeakers(channel Channel, serverUserIDs ...string) error {
+	speakersChannel, ok := channel.(SpeakersChannel)
+	if !ok {
+		return nil
+	}
+	return speakersChannel.AllowOnlySpeakers(serverUserIDs...)
+}
+
+// AllowAllSpeakers calls AllowAllSpeakers, if the channel implements SpeakersChannel.
+func AllowAllSpeakers(channel Channel) error {
+	speakersChannel, ok := channel.(SpeakersChannel)
+	if !ok {
+		return nil
+	}
+	return speakersChannel.AllowAllSpeakers()
+}
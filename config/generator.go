@@ -0,0 +1,353 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+// Generator produces the balanced configs for any number of players (see Generate).
+//
+// Every role has the strength weight (see DefaultRoleWeights).
+// The config is balanced, if the strength of the peaceful team is close to the strength of all others.
+// Peaceful and Mafia are the basic roles and can be repeated, all other roles are used only once.
+// The configs, in which a team has already won at the start, are never generated (see DefaultWinner).
+
+var (
+	TooFewPlayersToGenerateErr = errors.New("too few players to generate config")
+	TooManyMandatoryRolesErr   = errors.New("mandatory roles are more than players")
+	CannotBalanceConfigErr     = errors.New("cannot balance config with the roles")
+)
+
+// DefaultRoleWeights presents the strength of the roles for their teams.
+// Roles without the weight have weight 1.
+var DefaultRoleWeights = map[*roles.Role]float64{
+	roles.Peaceful:  1,
+	roles.Citizen:   1.2,
+	roles.Whore:     1.6,
+	roles.Doctor:    1.8,
+	roles.Detective: 2,
+	roles.Fool:      0.5,
+	roles.Mafia:     2.5,
+	roles.Don:       3,
+	roles.Maniac:    3,
+}
+
+// WinnerFunc returns the team, which has already won with the config before the first night, or nil.
+//
+// The config package does not know the win conditions, see registry.ConfigWinner.
+type WinnerFunc func(cfg *RolesConfig) *roles.Team
+
+// DefaultWinner is the WinnerFunc of NewGenerator.
+// It is set by the registry package to registry.ConfigWinner(nil, nil), with the registered win conditions.
+var DefaultWinner WinnerFunc
+
+type GeneratorOption func(g *Generator)
+
+// GeneratorWeightsOpt replaces the weights of the roles.
+func GeneratorWeightsOpt(weights map[*roles.Role]float64) GeneratorOption {
+	return func(g *Generator) {
+		for role, weight := range weights {
+			g.weights[role] = weight
+		}
+	}
+}
+
+// GeneratorAllowedRolesOpt sets the pool of the roles. Default - all roles.MappedRoles.
+func GeneratorAllowedRolesOpt(allowed ...*roles.Role) GeneratorOption {
+	return func(g *Generator) { g.allowed = allowed }
+}
+
+// GeneratorMandatoryRoleOpt the role will be in the config count times (even if it is not in the pool).
+func GeneratorMandatoryRoleOpt(role *roles.Role, count int) GeneratorOption {
+	return func(g *Generator) { g.mandatory[role] = count }
+}
+
+// GeneratorChaosLevelOpt sets the chaos level from 0 to 1.
+//
+// 0 - only the balance matters, there are no chaos roles.
+// 1 - the config gets as many special roles as possible, and a chaos role for every 7 players.
+func GeneratorChaosLevelOpt(level float64) GeneratorOption {
+	return func(g *Generator) { g.chaos = min(max(level, 0), 1) }
+}
+
+// GeneratorChaosRolesOpt sets the roles, that are added by the chaos level. Default - Maniac and Fool.
+func GeneratorChaosRolesOpt(chaosRoles ...*roles.Role) GeneratorOption {
+	return func(g *Generator) { g.chaosRoles = chaosRoles }
+}
+
+// GeneratorRandOpt is used to choose between equally balanced configs.
+// Without it, the generator is deterministic.
+func GeneratorRandOpt(rnd *rand.Rand) GeneratorOption {
+	return func(g *Generator) { g.rand = rnd }
+}
+
+// GeneratorWinnerOpt replaces the check, that no team has already won at the start,
+// for example, with the house rules of the game (see registry.ConfigWinner). Default - DefaultWinner.
+func GeneratorWinnerOpt(winner WinnerFunc) GeneratorOption {
+	return func(g *Generator) { g.winner = winner }
+}
+
+type Generator struct {
+	weights    map[*roles.Role]float64
+	allowed    []*roles.Role
+	mandatory  map[*roles.Role]int
+	chaos      float64
+	chaosRoles []*roles.Role
+	rand       *rand.Rand
+	winner     WinnerFunc
+}
+
+func NewGenerator(opts ...GeneratorOption) *Generator {
+	g := &Generator{
+		weights:    make(map[*roles.Role]float64),
+		mandatory:  make(map[*roles.Role]int),
+		chaosRoles: []*roles.Role{roles.Maniac, roles.Fool},
+		winner:     DefaultWinner,
+	}
+	for role, weight := range DefaultRoleWeights {
+		g.weights[role] = weight
+	}
+	g.allowed = roles.GetAllSortedRoles()
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Weight returns the strength weight of the role.
+func (g *Generator) Weight(role *roles.Role) float64 {
+	if weight, ok := g.weights[role]; ok {
+		return weight
+	}
+	return 1
+}
+
+// Strength returns the strength of the team in the config.
+func (g *Generator) Strength(cfg *RolesConfig, team roles.Team) float64 {
+	var strength float64
+	for _, roleCfg := range cfg.RolesMp {
+		if roleCfg.Role.Team == team {
+			strength += g.Weight(roleCfg.Role) * float64(roleCfg.Count)
+		}
+	}
+	return strength
+}
+
+// Imbalance returns the difference between the strength of the peaceful team and all others.
+// Positive means, that the peaceful team is stronger.
+func (g *Generator) Imbalance(cfg *RolesConfig) float64 {
+	var imbalance float64
+	for _, roleCfg := range cfg.RolesMp {
+		strength := g.Weight(roleCfg.Role) * float64(roleCfg.Count)
+		if roleCfg.Role.Team == roles.PeacefulTeam {
+			imbalance += strength
+		} else {
+			imbalance -= strength
+		}
+	}
+	return imbalance
+}
+
+// Generate returns the balanced config for playersCount players.
+//
+// The number of the mafia team players is from 1 to a third of the players.
+// Among all compositions the generator chooses the one with the least imbalance,
+// each special peaceful role reduces the imbalance by the chaos level.
+// The compositions with the problems of RolesConfig.Validate (including the team, which has already won) are skipped.
+func (g *Generator) Generate(playersCount int) (*RolesConfig, error) {
+	if playersCount < 2 {
+		return nil, fmt.Errorf("%w: %v", TooFewPlayersToGenerateErr, playersCount)
+	}
+
+	base := make(map[*roles.Role]int)
+	total := 0
+	for role, count := range g.mandatory {
+		if count <= 0 {
+			continue
+		}
+		base[role] = count
+		total += count
+	}
+	if total > playersCount {
+		return nil, fmt.Errorf("%w: %v > %v", TooManyMandatoryRolesErr, total, playersCount)
+	}
+
+	pool := g.getPool(base)
+	isAllowed := func(role *roles.Role) bool {
+		for _, allowedRole := range g.allowed {
+			if allowedRole == role {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Chaos roles are added first, leaving the place for the mafia and the peaceful.
+	chaosCount := int(math.Round(g.chaos * float64(playersCount) / 7))
+	for _, role := range pool {
+		if chaosCount == 0 || total >= playersCount-2 {
+			break
+		}
+		if g.isChaosRole(role) {
+			base[role]++
+			total++
+			chaosCount--
+		}
+	}
+
+	var (
+		mafiaSpecials    []*roles.Role
+		peacefulSpecials []*roles.Role
+	)
+	for _, role := range g.getPool(base) {
+		switch {
+		case g.isChaosRole(role) || role == roles.Mafia || role == roles.Peaceful:
+		case role.Team == roles.MafiaTeam:
+			mafiaSpecials = append(mafiaSpecials, role)
+		case role.Team == roles.PeacefulTeam:
+			peacefulSpecials = append(peacefulSpecials, role)
+		}
+	}
+	// Not to go through too many subsets.
+	peacefulSpecials = peacefulSpecials[:min(len(peacefulSpecials), 12)]
+
+	var (
+		best      []*RolesConfig
+		bestScore = math.Inf(1)
+	)
+	mandatoryMafia := countByTeam(base, roles.MafiaTeam)
+	for mafiaCount := max(1, mandatoryMafia); mafiaCount <= max(1, (playersCount-1)/3, mandatoryMafia); mafiaCount++ {
+		counts := copyCounts(base)
+		left := mafiaCount - mandatoryMafia
+		// Special roles of mafia, but at least one basic Mafia, if it is allowed.
+		for _, role := range mafiaSpecials {
+			if left <= 1 && isAllowed(roles.Mafia) {
+				break
+			}
+			if left == 0 {
+				break
+			}
+			counts[role]++
+			left--
+		}
+		if left > 0 {
+			if !isAllowed(roles.Mafia) {
+				continue
+			}
+			counts[roles.Mafia] += left
+		}
+
+		peacefulLeft := playersCount - total - (mafiaCount - mandatoryMafia)
+		if peacefulLeft < 0 {
+			continue
+		}
+		for mask := 0; mask < 1<<len(peacefulSpecials); mask++ {
+			specialsCount := bits.OnesCount(uint(mask))
+			if specialsCount > peacefulLeft {
+				continue
+			}
+			fill := peacefulLeft - specialsCount
+			if fill > 0 && !isAllowed(roles.Peaceful) {
+				continue
+			}
+			cfg := countsToConfig(counts)
+			for i, role := range peacefulSpecials {
+				if mask&(1<<i) != 0 {
+					addToConfig(cfg, role, 1)
+				}
+			}
+			addToConfig(cfg, roles.Peaceful, fill)
+			if len(cfg.Validate(ValidateWinnerOpt(g.winner))) > 0 {
+				continue
+			}
+
+			score := math.Abs(g.Imbalance(cfg)) - g.chaos*float64(specialsCount)
+			switch {
+			case score < bestScore-1e-9:
+				bestScore = score
+				best = []*RolesConfig{cfg}
+			case math.Abs(score-bestScore) <= 1e-9:
+				best = append(best, cfg)
+			}
+		}
+	}
+	if len(best) == 0 {
+		return nil, fmt.Errorf("%w: %v players", CannotBalanceConfigErr, playersCount)
+	}
+	if g.rand != nil {
+		return best[g.rand.Intn(len(best))], nil
+	}
+	return best[0], nil
+}
+
+// getPool returns the allowed roles, which are not in counts (except the basic roles),
+// sorted by weight (the strongest first).
+func (g *Generator) getPool(counts map[*roles.Role]int) []*roles.Role {
+	var pool []*roles.Role
+	for _, role := range g.allowed {
+		if _, ok := counts[role]; ok && role != roles.Peaceful && role != roles.Mafia {
+			continue
+		}
+		pool = append(pool, role)
+	}
+	sort.Slice(pool, func(i, j int) bool {
+		if g.Weight(pool[i]) != g.Weight(pool[j]) {
+			return g.Weight(pool[i]) > g.Weight(pool[j])
+		}
+		return pool[i].Name < pool[j].Name
+	})
+	return pool
+}
+
+func (g *Generator) isChaosRole(role *roles.Role) bool {
+	for _, chaosRole := range g.chaosRoles {
+		if chaosRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+func countByTeam(counts map[*roles.Role]int, team roles.Team) int {
+	var count int
+	for role, roleCount := range counts {
+		if role.Team == team {
+			count += roleCount
+		}
+	}
+	return count
+}
+
+func copyCounts(counts map[*roles.Role]int) map[*roles.Role]int {
+	copied := make(map[*roles.Role]int, len(counts))
+	for role, count := range counts {
+		copied[role] = count
+	}
+	return copied
+}
+
+func countsToConfig(counts map[*roles.Role]int) *RolesConfig {
+	cfg := &RolesConfig{RolesMp: make(map[string]*RoleConfig)}
+	for role, count := range counts {
+		addToConfig(cfg, role, count)
+	}
+	return cfg
+}
+
+func addToConfig(cfg *RolesConfig, role *roles.Role, count int) {
+	if count <= 0 {
+		return
+	}
+	cfg.PlayersCount += count
+	if roleCfg, ok := cfg.RolesMp[role.Name]; ok {
+		roleCfg.Count += count
+		return
+	}
+	cfg.RolesMp[role.Name] = &RoleConfig{Role: role, Count: count}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+// This file allows to load the configs at runtime, instead of editing config.go.
+//
+// File is the list of the configs, for example, in YAML:
+//
+//	- playersCount: 5
+//	  roles:
+//	    Mafia: 1
+//	    Doctor: 1
+//	    Peaceful: 3
+//
+// Use the result as Configs (or a part of it).
+
+type Format string
+
+const (
+	JSONFormat Format = "json"
+	YAMLFormat Format = "yaml"
+)
+
+var (
+	UnsupportedFormatErr   = errors.New("unsupported config format")
+	UnknownRoleNameErr     = errors.New("unknown role name")
+	NonPositiveCountErr    = errors.New("role count must be positive")
+	CountsMismatchErr      = errors.New("sum of role counts does not match players count")
+	OneTeamConfigErr       = errors.New("config must have at least two teams")
+	NoRoleChannelErr       = errors.New("night role has no channel")
+	EmptyConfigsErr        = errors.New("no configs")
+	DuplicateRoleConfigErr = errors.New("duplicate role in config")
+)
+
+// fileConfig presents one config in the file.
+type fileConfig struct {
+	PlayersCount int            `json:"playersCount" yaml:"playersCount"`
+	Roles        map[string]int `json:"roles" yaml:"roles"`
+}
+
+type LoadOption func(o *loadOptions)
+
+type loadOptions struct {
+	channelRoles map[string]bool
+	validateOpts []ValidateOption
+}
+
+// LoadRoleChannelsOpt sets the names of the roles, which have the channels in your application.
+// Default - roles.GetAllNightInteractionRolesNames.
+func LoadRoleChannelsOpt(roleNames ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.channelRoles = make(map[string]bool)
+		for _, roleName := range roleNames {
+			o.channelRoles[roleName] = true
+		}
+	}
+}
+
+// LoadValidateOpt sets the options of RolesConfig.Validate, with which the configs are checked.
+func LoadValidateOpt(opts ...ValidateOption) LoadOption {
+	return func(o *loadOptions) { o.validateOpts = opts }
+}
+
+// LoadConfigs parses the configs from r in the format, and validates them:
+// known role names, every night role has a channel, and all RolesConfig.Validate checks
+// (counts sum to PlayersCount, at least two teams, etc.).
+//
+// All problems are returned together (multierror), each of them wraps one of the errors of the package.
+func LoadConfigs(r io.Reader, format Format, opts ...LoadOption) (map[int]*ConfigsByPlayerCount, error) {
+	options := loadOptions{channelRoles: make(map[string]bool)}
+	for _, roleName := range roles.GetAllNightInteractionRolesNames() {
+		options.channelRoles[roleName] = true
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	fileConfigs, err := decodeFileConfigs(r, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileConfigs) == 0 {
+		return nil, EmptyConfigsErr
+	}
+
+	var (
+		allErr error
+		loaded = make(map[int]*ConfigsByPlayerCount)
+	)
+	for i, fileCfg := range fileConfigs {
+		cfg, cfgErr := fileCfg.toRolesConfig(options)
+		if cfgErr != nil {
+			allErr = multierror.Append(allErr, fmt.Errorf("config #%v (%v players): %w", i, fileCfg.PlayersCount, cfgErr))
+			continue
+		}
+		if loaded[cfg.PlayersCount] == nil {
+			loaded[cfg.PlayersCount] = &ConfigsByPlayerCount{}
+		}
+		*loaded[cfg.PlayersCount] = append(*loaded[cfg.PlayersCount], cfg)
+	}
+	if allErr != nil {
+		return nil, allErr
+	}
+	return loaded, nil
+}
+
+func decodeFileConfigs(r io.Reader, format Format) ([]fileConfig, error) {
+	var fileConfigs []fileConfig
+	switch format {
+	case JSONFormat:
+		if err := json.NewDecoder(r).Decode(&fileConfigs); err != nil {
+			return nil, err
+		}
+	case YAMLFormat:
+		if err := yaml.NewDecoder(r).Decode(&fileConfigs); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %v", UnsupportedFormatErr, format)
+	}
+	return fileConfigs, nil
+}
+
+func (c fileConfig) toRolesConfig(options loadOptions) (*RolesConfig, error) {
+	var err error
+	cfg := &RolesConfig{
+		PlayersCount: c.PlayersCount,
+		RolesMp:      make(map[string]*RoleConfig),
+	}
+
+	// Sorted, so the errors are in the same order.
+	roleNames := make([]string, 0, len(c.Roles))
+	for roleName := range c.Roles {
+		roleNames = append(roleNames, roleName)
+	}
+	sort.Strings(roleNames)
+
+	for _, roleName := range roleNames {
+		role, ok := roles.GetRoleByName(roleName)
+		if !ok {
+			err = multierror.Append(err, fmt.Errorf("%w: %v", UnknownRoleNameErr, roleName))
+			continue
+		}
+		cfg.RolesMp[role.Name] = &RoleConfig{Role: role, Count: c.Roles[roleName]}
+		if role.NightVoteOrder != -1 && !options.channelRoles[role.Name] {
+			err = multierror.Append(err, fmt.Errorf("%w: %v", NoRoleChannelErr, roleName))
+		}
+	}
+	for _, problem := range cfg.Validate(options.validateOpts...) {
+		err = multierror.Append(err, problem)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
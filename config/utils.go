@@ -9,8 +9,26 @@ import (
 )
 
 func (cfg *RolesConfig) GetShuffledRolesConfig() []*roles.Role {
+	return cfg.getShuffledRoles(rand.Shuffle)
+}
+
+// GetShuffledRolesConfigWithRand same as GetShuffledRolesConfig, but the deal depends only on rnd.
+// So, the same seed of rnd gives the same deal.
+func (cfg *RolesConfig) GetShuffledRolesConfigWithRand(rnd *rand.Rand) []*roles.Role {
+	if rnd == nil {
+		return cfg.GetShuffledRolesConfig()
+	}
+	return cfg.getShuffledRoles(rnd.Shuffle)
+}
+
+func (cfg *RolesConfig) getShuffledRoles(shuffle func(n int, swap func(i, j int))) []*roles.Role {
+	// Map iteration order is random, so sort the roles before shuffling.
+	roleNames := lo.Keys(cfg.RolesMp)
+	sort.Strings(roleNames)
+
 	var rolesArr []*roles.Role
-	for _, roleConfig := range cfg.RolesMp {
+	for _, roleName := range roleNames {
+		roleConfig := cfg.RolesMp[roleName]
 		roleCount := roleConfig.Count
 		role := roleConfig.Role
 		for i := 1; i <= roleCount; i++ {
@@ -18,7 +36,7 @@ func (cfg *RolesConfig) GetShuffledRolesConfig() []*roles.Role {
 		}
 	}
 
-	rand.Shuffle(cfg.PlayersCount, func(i, j int) {
+	shuffle(len(rolesArr), func(i, j int) {
 		rolesArr[i], rolesArr[j] = rolesArr[j], rolesArr[i]
 	})
 
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+// This file contains the checks of the config (for example, after editing it in the admin panel).
+//
+// Validate finds the problems, with which the game can not be played correctly,
+// Lint finds the balance issues, with which the game can be played, but is not interesting.
+
+var (
+	AlreadyWonAtStartErr      = errors.New("team has already won at the start")
+	DonWithoutMafiaErr        = errors.New("don without mafia")
+	DetectiveInOneTeamGameErr = errors.New("detective in one team game")
+)
+
+// Lint warnings.
+var (
+	ImbalancedConfigWarning = errors.New("config is imbalanced")
+	TooManyMafiaWarning     = errors.New("too many mafia players")
+	NoActivePeacefulWarning = errors.New("peaceful team has no night roles")
+	ChaosInSmallGameWarning = errors.New("chaos roles in small game")
+)
+
+// Problem presents one problem of the config.
+// Err is one of the errors (or warnings) above, Roles are the names of the roles with the problem.
+type Problem struct {
+	Err     error    `json:"err"`
+	Roles   []string `json:"roles,omitempty"`
+	Details string   `json:"details,omitempty"`
+}
+
+func (p Problem) Error() string {
+	message := p.Err.Error()
+	if len(p.Roles) != 0 {
+		message += " (" + strings.Join(p.Roles, ", ") + ")"
+	}
+	if p.Details != "" {
+		message += ": " + p.Details
+	}
+	return message
+}
+
+func (p Problem) Unwrap() error { return p.Err }
+
+type Problems []Problem
+
+// Has reports whether there is the problem with err.
+func (ps Problems) Has(err error) bool {
+	for _, p := range ps {
+		if errors.Is(p.Err, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns all problems as multierror, or nil, if there are no problems.
+func (ps Problems) Err() error {
+	var err error
+	for _, p := range ps {
+		err = multierror.Append(err, p)
+	}
+	return err
+}
+
+// ____________
+// Validate
+// ____________
+
+type ValidateOption func(o *validateOptions)
+
+type validateOptions struct {
+	winner WinnerFunc
+}
+
+// ValidateWinnerOpt enables the check, that no team has already won at the start.
+func ValidateWinnerOpt(winner WinnerFunc) ValidateOption {
+	return func(o *validateOptions) { o.winner = winner }
+}
+
+// Validate returns the problems, with which the game can not be played correctly:
+// incorrect role counts, duplicate roles, counts mismatch, one team, Don without Mafia, Detective in one team game,
+// and (with ValidateWinnerOpt) the team, which has already won.
+//
+// nil means, that the config is valid.
+func (cfg *RolesConfig) Validate(opts ...ValidateOption) Problems {
+	var options validateOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if cfg == nil {
+		return Problems{{Err: EmptyConfigsErr}}
+	}
+
+	var problems Problems
+
+	// Sorted, so the problems are in the same order.
+	keys := make([]string, 0, len(cfg.RolesMp))
+	for key := range cfg.RolesMp {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var (
+		total      int
+		keysByRole = make(map[*roles.Role][]string)
+	)
+	for _, key := range keys {
+		roleCfg := cfg.RolesMp[key]
+		if roleCfg == nil || roleCfg.Role == nil {
+			problems = append(problems, Problem{Err: UnknownRoleNameErr, Roles: []string{key}})
+			continue
+		}
+		if roleCfg.Count <= 0 {
+			problems = append(problems, Problem{
+				Err: NonPositiveCountErr, Roles: []string{key}, Details: fmt.Sprint(roleCfg.Count),
+			})
+		}
+		if key != roleCfg.Role.Name {
+			problems = append(problems, Problem{
+				Err: DuplicateRoleConfigErr, Roles: []string{key},
+				Details: fmt.Sprintf("key does not match role %v", roleCfg.Role.Name),
+			})
+		}
+		keysByRole[roleCfg.Role] = append(keysByRole[roleCfg.Role], key)
+		total += max(roleCfg.Count, 0)
+	}
+	for _, key := range keys {
+		roleCfg := cfg.RolesMp[key]
+		if roleCfg == nil || roleCfg.Role == nil {
+			continue
+		}
+		// Report the duplicate once, by the first key.
+		if sameKeys := keysByRole[roleCfg.Role]; len(sameKeys) > 1 && sameKeys[0] == key {
+			problems = append(problems, Problem{Err: DuplicateRoleConfigErr, Roles: sameKeys})
+		}
+	}
+
+	if total != cfg.PlayersCount {
+		problems = append(problems, Problem{
+			Err: CountsMismatchErr, Details: fmt.Sprintf("%v != %v", total, cfg.PlayersCount),
+		})
+	}
+	teams := cfg.getValidTeams()
+	if len(teams) < 2 {
+		problems = append(problems, Problem{Err: OneTeamConfigErr})
+		if cfg.HasRole(roles.Detective) {
+			problems = append(problems, Problem{Err: DetectiveInOneTeamGameErr, Roles: []string{roles.Detective.Name}})
+		}
+	}
+	if cfg.HasRole(roles.Don) && !cfg.HasRole(roles.Mafia) {
+		problems = append(problems, Problem{Err: DonWithoutMafiaErr, Roles: []string{roles.Don.Name}})
+	}
+	if options.winner != nil && len(problems) == 0 {
+		if winner := options.winner(cfg); winner != nil {
+			problems = append(problems, Problem{Err: AlreadyWonAtStartErr, Details: roles.StringTeam[*winner]})
+		}
+	}
+	return problems
+}
+
+// getValidTeams same as GetTeamsByCfg, but skips the incorrect role configs.
+func (cfg *RolesConfig) getValidTeams() []roles.Team {
+	mpTeams := make(map[roles.Team]bool)
+	for _, roleCfg := range cfg.RolesMp {
+		if roleCfg != nil && roleCfg.Role != nil && roleCfg.Count > 0 {
+			mpTeams[roleCfg.Role.Team] = true
+		}
+	}
+	teams := make([]roles.Team, 0, len(mpTeams))
+	for team := range mpTeams {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i] < teams[j] })
+	return teams
+}
+
+// ____________
+// Lint
+// ____________
+
+type LintOption func(o *lintOptions)
+
+type lintOptions struct {
+	generator    *Generator
+	maxImbalance float64
+}
+
+// LintGeneratorOpt sets the generator, which weights are used to check the balance.
+// Default - NewGenerator().
+func LintGeneratorOpt(g *Generator) LintOption {
+	return func(o *lintOptions) { o.generator = g }
+}
+
+// LintMaxImbalanceOpt sets the maximum imbalance (see Generator.Imbalance) per player.
+// Default - 0.6.
+func LintMaxImbalanceOpt(maxImbalance float64) LintOption {
+	return func(o *lintOptions) { o.maxImbalance = maxImbalance }
+}
+
+// Lint returns the balance issues of the valid config.
+// It does not repeat the Validate problems, so call Validate first. The invalid config has no issues.
+func (cfg *RolesConfig) Lint(opts ...LintOption) Problems {
+	options := lintOptions{maxImbalance: 0.6}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.generator == nil {
+		options.generator = NewGenerator()
+	}
+
+	if cfg == nil || cfg.PlayersCount == 0 || len(cfg.Validate()) > 0 {
+		return nil
+	}
+
+	var problems Problems
+
+	imbalance := options.generator.Imbalance(cfg)
+	if math.Abs(imbalance) > options.maxImbalance*float64(cfg.PlayersCount) {
+		stronger := "peaceful team"
+		if imbalance < 0 {
+			stronger = "others"
+		}
+		problems = append(problems, Problem{
+			Err: ImbalancedConfigWarning, Details: fmt.Sprintf("%v is stronger by %.2f", stronger, math.Abs(imbalance)),
+		})
+	}
+
+	mafiaCount := cfg.GetPlayersCountByTeam(roles.MafiaTeam)
+	if mafiaCount > max(1, (cfg.PlayersCount-1)/3) {
+		problems = append(problems, Problem{
+			Err: TooManyMafiaWarning, Details: fmt.Sprintf("%v of %v", mafiaCount, cfg.PlayersCount),
+		})
+	}
+
+	var (
+		hasActivePeaceful bool
+		chaosRoles        []string
+	)
+	for _, roleCfg := range cfg.RolesMp {
+		if roleCfg.Role.Team == roles.PeacefulTeam && roleCfg.Role.NightVoteOrder != -1 {
+			hasActivePeaceful = true
+		}
+		if options.generator.isChaosRole(roleCfg.Role) {
+			chaosRoles = append(chaosRoles, roleCfg.Role.Name)
+		}
+	}
+	if !hasActivePeaceful && cfg.PlayersCount >= 7 {
+		problems = append(problems, Problem{Err: NoActivePeacefulWarning})
+	}
+	if len(chaosRoles) != 0 && cfg.PlayersCount < 6 {
+		sort.Strings(chaosRoles)
+		problems = append(problems, Problem{Err: ChaosInSmallGameWarning, Roles: chaosRoles})
+	}
+	return problems
+}
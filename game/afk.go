@@ -0,0 +1,286 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+
+	channelPack "github.com/https-whoyan/MafiaCore/channel"
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+)
+
+// This file contains the players, who have left the game.
+//
+// The application can mark the player as disconnected (SetDisconnected), or the game finds out itself,
+// that the player is AFK: he has missed AFKPhases voting phases in a row (see AFKOpt).
+// Missed phase is the night voting of his role, which ended by the timer,
+// or the day voting (except the nomination), which ended by the timer without his vote.
+//
+// At the end of the night and of the day the game applies AFKPolicy to such players:
+// kills them with player.Left, or only informs the application (PlayerAFKInfo),
+// which may substitute another user (ReplacePlayer) or kick the player (KickPlayer).
+
+var (
+	PlayerNotFoundErr = errors.New("player not found")
+	AlreadyInGameErr  = errors.New("user is already in game")
+)
+
+// AFKPolicy presents what the game does with the AFK (or disconnected) player.
+type AFKPolicy uint8
+
+const (
+	// KickAFKPolicy the player is killed with player.Left.
+	KickAFKPolicy AFKPolicy = iota
+	// NotifyAFKPolicy the game only sends PlayerAFKInfo, once, until the player votes again.
+	NotifyAFKPolicy
+)
+
+// AFKOpt sets the number of the missed phases in a row, after which the player is AFK, and the policy.
+// 0 phases means, that only the disconnected players (see SetDisconnected) are AFK.
+//
+// Default: 0, KickAFKPolicy.
+func AFKOpt(phases int, policy AFKPolicy) Option {
+	return func(g *Game) {
+		g.afkPhases = max(phases, 0)
+		g.afkPolicy = policy
+	}
+}
+
+// SetDisconnected marks the player as disconnected (or back, if !isDisconnected).
+// The disconnected player is AFK at the end of the current night or day.
+func (g *Game) SetDisconnected(id playerPack.IDType, isDisconnected bool) error {
+	g.Lock()
+	defer g.Unlock()
+	if g.active.GetByIDType(id) == nil {
+		return fmt.Errorf("%w: %v", PlayerNotFoundErr, id)
+	}
+	if isDisconnected {
+		g.disconnected[id] = true
+	} else {
+		delete(g.disconnected, id)
+		delete(g.notifiedAFK, id)
+	}
+	return nil
+}
+
+// IsDisconnected reports whether the player is marked as disconnected.
+func (g *Game) IsDisconnected(id playerPack.IDType) bool {
+	g.RLock()
+	defer g.RUnlock()
+	return g.disconnected[id]
+}
+
+// GetMissedPhases returns the number of the voting phases in a row, missed by the player.
+func (g *Game) GetMissedPhases(id playerPack.IDType) int {
+	g.RLock()
+	defer g.RUnlock()
+	return g.missedPhases[id]
+}
+
+// KickPlayer kills the player with player.Left at the end of the current night or day,
+// so the votes of the current phase stay correct.
+func (g *Game) KickPlayer(id playerPack.IDType) error {
+	g.Lock()
+	defer g.Unlock()
+	if g.active.GetByIDType(id) == nil {
+		return fmt.Errorf("%w: %v", PlayerNotFoundErr, id)
+	}
+	g.leaving[id] = playerPack.Left
+	return nil
+}
+
+// ReplacePlayer substitutes the user into the seat of the player: the same ID, role and votes.
+// The user takes the place of the player in the channels and is renamed by the rename mode of the game.
+func (g *Game) ReplacePlayer(id playerPack.IDType, user *playerPack.NonPlayingPlayer) error {
+	if user == nil {
+		return fmt.Errorf("%w: nil user", PlayerNotFoundErr)
+	}
+	g.Lock()
+	p := g.active.GetByIDType(id)
+	if p == nil {
+		g.Unlock()
+		return fmt.Errorf("%w: %v", PlayerNotFoundErr, id)
+	}
+	for _, tag := range playerPack.GetTags(g.startPlayers, g.spectators) {
+		if tag == user.Tag {
+			g.Unlock()
+			return fmt.Errorf("%w: %v", AlreadyInGameErr, user.Tag)
+		}
+	}
+	old := p.NonPlayingPlayer
+	p.NonPlayingPlayer = *user
+	for i, startPlayer := range *g.startPlayers {
+		if startPlayer.Tag == old.Tag {
+			newStartPlayer := *user
+			(*g.startPlayers)[i] = &newStartPlayer
+		}
+	}
+	delete(g.bots, old.Tag)
+	g.resetAFK(id)
+	g.record(PlayerReplacedEvent, PlayerReplacedData{
+		PlayerID: id,
+		OldTag:   old.Tag,
+		User:     *user,
+	})
+	mainChannel, roleChannel := g.mainChannel, g.roleChannels[p.Role]
+	// The muted player is the spectator of the role channel, while his role is voting.
+	isSpectator := p.InteractionStatus == playerPack.Muted && g.nightVoting == p.Role
+	replaced := *p
+	g.Unlock()
+
+	var err error
+	appendErr := func(newErr error) {
+		if newErr != nil {
+			err = multierror.Append(err, newErr)
+		}
+	}
+	if roleChannel != nil {
+		appendErr(roleChannel.RemoveUser(old.Tag))
+		if isSpectator {
+			appendErr(roleChannel.AddSpectator(user.Tag))
+		} else {
+			appendErr(roleChannel.AddPlayer(user.Tag))
+		}
+	}
+	appendErr(mainChannel.RemoveUser(old.Tag))
+	appendErr(mainChannel.AddPlayer(user.Tag))
+	appendErr(g.renameReplaced(old, &replaced, mainChannel, roleChannel))
+
+	g.Lock()
+	if p.Tag == replaced.Tag {
+		p.Nick = replaced.Nick
+	}
+	g.Unlock()
+	return err
+}
+
+// renameReplaced returns the nick to the old user and renames the new one, as Init and finish do.
+func (g *Game) renameReplaced(old playerPack.NonPlayingPlayer, p *playerPack.Player,
+	mainChannel channelPack.MainChannel, roleChannel channelPack.RoleChannel) error {
+	var channelIDs []string
+	switch g.renameMode {
+	case NotRenameMode:
+		return nil
+	case RenameInGuildMode:
+		channelIDs = []string{""}
+	case RenameOnlyInMainChannelMode:
+		channelIDs = []string{mainChannel.GetServerID()}
+	case RenameInAllChannelsMode:
+		channelIDs = []string{mainChannel.GetServerID()}
+		if roleChannel != nil {
+			channelIDs = append(channelIDs, roleChannel.GetServerID())
+		}
+	default:
+		return errors.New("invalid rename mode")
+	}
+
+	var err error
+	for _, channelID := range channelIDs {
+		if renameErr := old.RenameUserAfterGame(g.renameProvider, channelID, g.infoLogger); renameErr != nil {
+			err = multierror.Append(err, renameErr)
+		}
+		if renameErr := p.RenameAfterGettingID(g.renameProvider, channelID, g.infoLogger); renameErr != nil {
+			err = multierror.Append(err, renameErr)
+		}
+	}
+	return err
+}
+
+// ____________
+// Internal
+// ____________
+
+// resetAFK used under the game lock.
+func (g *Game) resetAFK(id playerPack.IDType) {
+	delete(g.missedPhases, id)
+	delete(g.disconnected, id)
+	delete(g.notifiedAFK, id)
+	delete(g.leaving, id)
+}
+
+// markVoted is called on every vote of the player himself.
+//
+// Used under the game lock.
+func (g *Game) markVoted(id playerPack.IDType) {
+	delete(g.missedPhases, id)
+	delete(g.notifiedAFK, id)
+}
+
+// markMissed used under the game lock.
+func (g *Game) markMissed(ids ...playerPack.IDType) {
+	for _, id := range ids {
+		g.missedPhases[id]++
+	}
+}
+
+// isAFK used under the game lock.
+func (g *Game) isAFK(id playerPack.IDType) bool {
+	return g.disconnected[id] || (g.afkPhases > 0 && g.missedPhases[id] >= g.afkPhases)
+}
+
+// handleAFK applies AFKPolicy to the AFK players, and kills the players of KickPlayer and ForceKill.
+// Called at the end of the night and of the day.
+func (g *Game) handleAFK() {
+	if g.isInterrupted() {
+		return
+	}
+	g.Lock()
+	var (
+		left     []playerPack.IDType
+		reasons  = make(map[playerPack.IDType]playerPack.DeadReason)
+		notified []PlayerAFKInfo
+	)
+	for _, id := range g.active.GetIDs() {
+		reason, isLeaving := g.leaving[id]
+		switch {
+		case isLeaving:
+			left = append(left, id)
+			reasons[id] = reason
+		case !g.isAFK(id):
+			continue
+		case g.afkPolicy == KickAFKPolicy:
+			left = append(left, id)
+			reasons[id] = playerPack.Left
+		case !g.notifiedAFK[id]:
+			g.notifiedAFK[id] = true
+			notified = append(notified, PlayerAFKInfo{
+				PlayerID:       id,
+				MissedPhases:   g.missedPhases[id],
+				IsDisconnected: g.disconnected[id],
+			})
+		}
+	}
+
+	leftPlayers := make(playerPack.Players)
+	for _, id := range left {
+		reason := reasons[id]
+		leftPlayers[id] = g.active.GetByIDType(id)
+		g.active.ToDead(id, reason, g.nightCounter, g.dead)
+		died := PlayerDiedData{
+			PlayerID:  id,
+			Reason:    reason,
+			LivedDays: g.nightCounter,
+		}
+		g.record(PlayerDiedEvent, died)
+		g.sendPlayerDied(died)
+		g.resetAFK(id)
+	}
+	g.Unlock()
+
+	for _, info := range notified {
+		g.sendInfo(g.newPlayerAFKSignal(info))
+	}
+	if len(leftPlayers) == 0 {
+		return
+	}
+	for _, id := range leftPlayers.GetIDs() {
+		if reasons[id] == playerPack.Left {
+			safeSendErrSignal(g.errSender, g.messenger.Left.SendPlayerLeftMessage(g.mainChannel, leftPlayers[id]))
+		} else {
+			safeSendErrSignal(g.errSender, g.messenger.Host.SendPlayerKilledMessage(g.mainChannel, leftPlayers[id]))
+		}
+	}
+	// The left players have nothing to say, so no last words.
+	g.moveToSpectators(&leftPlayers)
+}
@@ -0,0 +1,264 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/registry"
+	rolesPack "github.com/https-whoyan/MafiaCore/roles"
+)
+
+// This file contains the bots, which fill the empty seats of the game (see BotOpt, Game.SetBot, Lobby.JoinBot).
+//
+// The bot gets the same information, which a human gets in his role channel and in the main channel:
+// his role and teammates, the invitation to vote, the result of the night check, the day messages.
+// And it votes by the same methods: SetNightVote, SetNightTwoVote and SetDayVote.
+// See the bot package for the reference implementation.
+
+var (
+	NilBotErr         = errors.New("nil bot")
+	BotNotInPlayerErr = errors.New("bot is not in start players")
+)
+
+// BotGame is the part of the game, available to the bot.
+type BotGame interface {
+	SetNightVote(nightVote NightVoteProviderInterface) error
+	SetNightTwoVote(nightVote NightTwoVoteProviderInterface) error
+	SetDayVote(dayVote DayVoteProviderInterface) error
+}
+
+// BotPlayer presents the player, controlled by the program.
+//
+// Init, NightResult and DayMessage are called by the game under its lock,
+// so they must not block and must not call the game.
+// NightVote and DayVote are called in the new goroutines, because the game waits for the votes.
+// Only the active bots are called.
+type BotPlayer interface {
+	// Init is called at the start of the game (Run): p is the copy of the bot player with his ID and role,
+	// teammates are the players with the same role (they share the role channel).
+	Init(g BotGame, p playerPack.Player, teammates []playerPack.IDType)
+	// NightVote is called, when the role of the bot is voting.
+	// The bot must vote by SetNightVote (or SetNightTwoVote, if the role IsTwoVotes) before the deadline.
+	NightVote(info BotNightInfo)
+	// NightResult is called with the result of the night check of the role (Don, Detective).
+	NightResult(result BotNightResult)
+	// DayMessage is called with the message of the day, sent to the main channel.
+	DayMessage(message string)
+	// DayVote is called, when the day voting is opened. The bot must vote by SetDayVote.
+	DayVote(info BotDayInfo)
+}
+
+// BotNightInfo presents the invitation to the night vote.
+type BotNightInfo struct {
+	Night int
+	Role  *rolesPack.Role
+	// Active are the IDs of the players in the game.
+	Active []playerPack.IDType
+}
+
+// BotNightResult presents the result of the night check, the same as the message in the role channel.
+type BotNightResult struct {
+	Night   int
+	Role    *rolesPack.Role
+	Message InteractionMessage
+	registry.CheckResult
+}
+
+// BotDayInfo presents the opened day voting.
+type BotDayInfo struct {
+	Day      int
+	DayPhase DayPhase
+	// Candidates are the players, who can be voted for (nil means anyone).
+	Candidates []playerPack.IDType
+	// Active are the IDs of the players in the game.
+	Active []playerPack.IDType
+}
+
+// BotOpt sets the bot of the player with the tag (the player must be in the start players).
+// Use it with Restore, to return the bots to the restored game.
+func BotOpt(tag string, bot BotPlayer) Option {
+	return func(g *Game) { g.bots[tag] = bot }
+}
+
+// SetBot same as BotOpt. Must be called before Init.
+func (g *Game) SetBot(tag string, bot BotPlayer) error {
+	if bot == nil {
+		return fmt.Errorf("%w: %v", NilBotErr, tag)
+	}
+	g.Lock()
+	defer g.Unlock()
+	g.bots[tag] = bot
+	return nil
+}
+
+func (g *Game) removeBot(tag string) {
+	g.Lock()
+	defer g.Unlock()
+	delete(g.bots, tag)
+}
+
+// GetBots returns the IDs of the players, whose seats are filled by the bots.
+func (g *Game) GetBots() []playerPack.IDType {
+	g.RLock()
+	defer g.RUnlock()
+	return g.getBotIDs()
+}
+
+// ____________
+// Internal
+// ____________
+
+// validateBots used under the game lock.
+func (g *Game) validateBots() error {
+	tags := make(map[string]bool)
+	for _, tag := range g.startPlayers.GetTags() {
+		tags[tag] = true
+	}
+	var err error
+	for tag := range g.bots {
+		if !tags[tag] {
+			err = multierror.Append(err, fmt.Errorf("%w: %v", BotNotInPlayerErr, tag))
+		}
+	}
+	return err
+}
+
+// markBots marks the players and the start players with the bots.
+//
+// Used under the game lock.
+func (g *Game) markBots() {
+	for _, p := range *g.startPlayers {
+		if _, ok := g.bots[p.Tag]; ok {
+			p.IsBot = true
+		}
+	}
+	for _, p := range *g.active {
+		if _, ok := g.bots[p.Tag]; ok {
+			p.IsBot = true
+		}
+	}
+}
+
+// getBotIDs used under the game lock.
+func (g *Game) getBotIDs() []playerPack.IDType {
+	var ids []playerPack.IDType
+	for _, p := range *g.active {
+		if p.IsBot {
+			ids = append(ids, p.ID)
+		}
+	}
+	for _, deadPlayers := range *g.dead {
+		for _, p := range deadPlayers {
+			if p.IsBot {
+				ids = append(ids, p.ID)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// activeBots returns the active players with the bots, sorted by ID.
+//
+// Used under the game lock.
+func (g *Game) activeBots() []*playerPack.Player {
+	var players []*playerPack.Player
+	for _, p := range *g.active {
+		if _, ok := g.bots[p.Tag]; ok {
+			players = append(players, p)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
+
+func (g *Game) initBots() {
+	g.Lock()
+	defer g.Unlock()
+	g.markBots()
+	for _, p := range g.activeBots() {
+		teammates := g.active.SearchAllPlayersWithRole(p.Role).GetIDs()
+		g.bots[p.Tag].Init(g, *p, teammates)
+	}
+}
+
+// inviteBotToNightVote invites the first not muted bot with the role to vote.
+// The vote of one player can decide for the role (see TeamVotePolicy), so the people lead:
+// if there is the not muted human with the role, the bots wait for his vote.
+func (g *Game) inviteBotToNightVote(role *rolesPack.Role) {
+	g.RLock()
+	defer g.RUnlock()
+	var voter *playerPack.Player
+	for _, id := range g.active.SearchAllPlayersWithRole(role).GetIDs() {
+		p := g.active.GetByIDType(id)
+		if p.InteractionStatus == playerPack.Muted {
+			continue
+		}
+		if _, isBot := g.bots[p.Tag]; !isBot {
+			return
+		}
+		if voter == nil {
+			voter = p
+		}
+	}
+	if voter == nil {
+		return
+	}
+	info := BotNightInfo{
+		Night:  g.nightCounter,
+		Role:   role,
+		Active: g.active.GetIDs(),
+	}
+	go g.bots[voter.Tag].NightVote(info)
+}
+
+// sendNightResultToBots sends the result of the night check of the voter to the bots with his role.
+func (g *Game) sendNightResultToBots(voter *playerPack.Player, message *InteractionMessage) {
+	g.RLock()
+	defer g.RUnlock()
+	if len(g.bots) == 0 {
+		return
+	}
+	check := registry.Check(g.env(), voter)
+	if check == nil {
+		return
+	}
+	result := BotNightResult{
+		Night:       g.nightCounter,
+		Role:        voter.Role,
+		CheckResult: *check,
+	}
+	if message != nil {
+		result.Message = *message
+	}
+	for _, p := range g.activeBots() {
+		if p.Role == voter.Role {
+			g.bots[p.Tag].NightResult(result)
+		}
+	}
+}
+
+func (g *Game) sendDayMessageToBots(message string) {
+	g.RLock()
+	defer g.RUnlock()
+	for _, p := range g.activeBots() {
+		g.bots[p.Tag].DayMessage(message)
+	}
+}
+
+// inviteBotsToDayVote used under the game lock.
+func (g *Game) inviteBotsToDayVote() {
+	info := BotDayInfo{
+		Day:        g.nightCounter,
+		DayPhase:   g.dayPhase,
+		Candidates: g.dayCandidates,
+		Active:     g.active.GetIDs(),
+	}
+	for _, p := range g.activeBots() {
+		go g.bots[p.Tag].DayVote(info)
+	}
+}
@@ -2,7 +2,10 @@ package game
 
 import (
 	"encoding/json"
-	"github.com/LastPossum/kamino"
+	"errors"
+	"fmt"
+	"sort"
+
 	channelPack "github.com/https-whoyan/MafiaCore/channel"
 	configPack "github.com/https-whoyan/MafiaCore/config"
 	playerPack "github.com/https-whoyan/MafiaCore/player"
@@ -24,6 +27,11 @@ type DeepCloneGame struct {
 
 	RoleChannels map[*rolesPack.Role]channelPack.RoleChannel `bson:"role_channels" json:"role_channels" yaml:"role_channels" db:"role_channels" xml:"role_channels" xlsx:"role_channels"`
 
+	// Server IDs of the channels, so the channels can be found after decoding of the clone.
+	// Key of RoleChannelIDs - role name.
+	MainChannelID  string            `bson:"main_channel_id" json:"main_channel_id" yaml:"main_channel_id" db:"main_channel_id" xml:"main_channel_id" xlsx:"main_channel_id"`
+	RoleChannelIDs map[string]string `bson:"role_channel_ids" json:"role_channel_ids" yaml:"role_channel_ids" db:"role_channel_ids" xml:"role_channel_ids" xlsx:"role_channel_ids"`
+
 	MainChannel     channelPack.MainChannel `bson:"main_channel" json:"main_channel" yaml:"main_channel" db:"main_channel" xml:"main_channel" xlsx:"main_channel"`
 	NightVoting     *rolesPack.Role         `bson:"night_voting" json:"night_voting" db:"night_voting" xml:"night_voting" xlsx:"night_voting"`
 	VoteForYourself bool                    `bson:"vote_for_yourself" bson:"vote_for_yourself"`
@@ -34,40 +42,272 @@ type DeepCloneGame struct {
 
 	NightLogs []NightLog `bson:"night_logs" json:"night_logs" yaml:"night_logs" db:"night_logs" xml:"night_logs" xlsx:"night_logs"`
 	DayLogs   []DayLog   `bson:"day_logs" json:"day_logs" yaml:"day_logs" db:"day_logs" xml:"day_logs" xlsx:"day_logs"`
+
+	// Seed of the game randomness. See RandSeedOpt.
+	Seed int64 `bson:"seed" json:"seed" yaml:"seed" db:"seed" xml:"seed" xlsx:"seed"`
+
+	// Key - player ID, value - count of his votes at the start of the current night.
+	// The unfinished night is rolled back to it (see Restore).
+	NightVotesStart map[playerPack.IDType]int `bson:"night_votes_start" json:"night_votes_start" yaml:"night_votes_start" db:"night_votes_start" xml:"night_votes_start" xlsx:"night_votes_start"`
 }
 
+// GetDeepClone returns the snapshot of the game.
+//
+// The channels are not copied: MainChannel and RoleChannels are the channels of the game.
 func (g *Game) GetDeepClone() (DeepCloneGame, error) {
-	deepCloneGame, err := kamino.Clone(g)
+	g.RLock()
+	defer g.RUnlock()
+	deepCloneGame, err := g.getUnlockedDeepClone()
 	if err != nil {
 		return DeepCloneGame{}, err
 	}
-	return DeepCloneGame{
-		GuildID:       deepCloneGame.guildID,
-		PlayersCount:  deepCloneGame.playersCount,
-		RolesConfig:   deepCloneGame.rolesConfig,
-		NightCounter:  deepCloneGame.nightCounter,
-		TimeStart:     deepCloneGame.timeStart,
-		EndTime:       deepCloneGame.endTime,
-		StartPlayers:  deepCloneGame.startPlayers,
-		Active:        deepCloneGame.active,
-		Dead:          deepCloneGame.dead,
-		Spectators:    deepCloneGame.spectators,
-		RoleChannels:  deepCloneGame.roleChannels,
-		MainChannel:   deepCloneGame.mainChannel,
-		NightVoting:   deepCloneGame.nightVoting,
-		VotePing:      deepCloneGame.votePing,
-		PreviousState: deepCloneGame.previousState,
-		State:         deepCloneGame.state,
-		NightLogs:     deepCloneGame.nightLogs,
-		DayLogs:       deepCloneGame.dayLogs,
-		RenameMode:    deepCloneGame.renameMode,
-	}, nil
+	deepCloneGame.MainChannel = g.mainChannel
+	deepCloneGame.RoleChannels = make(map[*rolesPack.Role]channelPack.RoleChannel)
+	deepCloneGame.RoleChannelIDs = make(map[string]string)
+	if g.mainChannel != nil {
+		deepCloneGame.MainChannelID = g.mainChannel.GetServerID()
+	}
+	for role, roleChannel := range g.roleChannels {
+		deepCloneGame.RoleChannels[role] = roleChannel
+		deepCloneGame.RoleChannelIDs[role.Name] = roleChannel.GetServerID()
+	}
+	return deepCloneGame, nil
 }
 
-func (g *DeepCloneGame) UnmarshalJSON(data []byte) error {
-	return json.Unmarshal(data, g)
+// ____________________
+// JSON
+// ____________________
+
+// DeepCloneGameJSONVersion is the version of the JSON format of DeepCloneGame.
+//
+// Roles are saved by names, channels by server IDs.
+// RoleChannels and MainChannel can not be saved, so they are empty after decoding.
+const DeepCloneGameJSONVersion = 1
+
+var UnsupportedJSONVersionErr = errors.New("unsupported json version of the game")
+
+type rolesConfigJSON struct {
+	PlayersCount int `json:"playersCount"`
+	// Key - role name, value - count of the role.
+	Roles map[string]int `json:"roles"`
+}
+
+type playerJSON struct {
+	playerPack.NonPlayingPlayer
+	ID                playerPack.IDType      `json:"id"`
+	Role              string                 `json:"role"`
+	Votes             []playerPack.IDType    `json:"votes"`
+	DayVote           playerPack.IDType      `json:"dayVote"`
+	LifeStatus        playerPack.AliveStatus `json:"lifeStatus"`
+	InteractionStatus playerPack.VoteStatus  `json:"interactionStatus"`
+}
+
+type deadPlayerJSON struct {
+	playerJSON
+	DeadReason playerPack.DeadReason `json:"deadReason"`
+	LivedDays  int                   `json:"livedDays"`
+}
+
+type deepCloneGameJSON struct {
+	Version         int                           `json:"version"`
+	GuildID         string                        `json:"guild_id"`
+	PlayersCount    int                           `json:"players_count"`
+	RolesConfig     *rolesConfigJSON              `json:"roles_config"`
+	NightCounter    int                           `json:"night_count"`
+	TimeStart       time.Time                     `json:"time_start"`
+	EndTime         time.Time                     `json:"end_time"`
+	StartPlayers    []playerPack.NonPlayingPlayer `json:"start_players"`
+	Active          []playerJSON                  `json:"active"`
+	Dead            []deadPlayerJSON              `json:"dead"`
+	Spectators      []playerPack.NonPlayingPlayer `json:"spectators"`
+	MainChannelID   string                        `json:"main_channel_id"`
+	RoleChannelIDs  map[string]string             `json:"role_channel_ids"`
+	NightVoting     string                        `json:"night_voting"`
+	NightVotesStart map[playerPack.IDType]int     `json:"night_votes_start"`
+	VoteForYourself bool                          `json:"vote_for_yourself"`
+	VotePing        int                           `json:"vote_ping"`
+	PreviousState   State                         `json:"previous_state"`
+	State           State                         `json:"state"`
+	RenameMode      RenameMode                    `json:"rename_mode"`
+	NightLogs       []NightLog                    `json:"night_logs"`
+	DayLogs         []DayLog                      `json:"day_logs"`
+	Seed            int64                         `json:"seed"`
+}
+
+func newPlayerJSON(p playerPack.Player) playerJSON {
+	var roleName string
+	if p.Role != nil {
+		roleName = p.Role.Name
+	}
+	return playerJSON{
+		NonPlayingPlayer:  p.NonPlayingPlayer,
+		ID:                p.ID,
+		Role:              roleName,
+		Votes:             p.Votes,
+		DayVote:           p.DayVote,
+		LifeStatus:        p.LifeStatus,
+		InteractionStatus: p.InteractionStatus,
+	}
+}
+
+func (p playerJSON) toPlayer() (*playerPack.Player, error) {
+	role, ok := rolesPack.GetRoleByName(p.Role)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", UnknownRoleErr, p.Role)
+	}
+	return &playerPack.Player{
+		NonPlayingPlayer:  p.NonPlayingPlayer,
+		ID:                p.ID,
+		Role:              role,
+		Votes:             p.Votes,
+		DayVote:           p.DayVote,
+		LifeStatus:        p.LifeStatus,
+		InteractionStatus: p.InteractionStatus,
+	}, nil
 }
 
 func (g DeepCloneGame) MarshalJSON() ([]byte, error) {
-	return json.Marshal(g)
+	j := deepCloneGameJSON{
+		Version:         DeepCloneGameJSONVersion,
+		GuildID:         g.GuildID,
+		PlayersCount:    g.PlayersCount,
+		NightCounter:    g.NightCounter,
+		TimeStart:       g.TimeStart,
+		EndTime:         g.EndTime,
+		MainChannelID:   g.MainChannelID,
+		RoleChannelIDs:  g.RoleChannelIDs,
+		NightVotesStart: g.NightVotesStart,
+		VoteForYourself: g.VoteForYourself,
+		VotePing:        g.VotePing,
+		PreviousState:   g.PreviousState,
+		State:           g.State,
+		RenameMode:      g.RenameMode,
+		NightLogs:       g.NightLogs,
+		DayLogs:         g.DayLogs,
+		Seed:            g.Seed,
+	}
+	if g.RolesConfig != nil {
+		j.RolesConfig = &rolesConfigJSON{
+			PlayersCount: g.RolesConfig.PlayersCount,
+			Roles:        make(map[string]int),
+		}
+		for roleName, roleCfg := range g.RolesConfig.RolesMp {
+			j.RolesConfig.Roles[roleName] = roleCfg.Count
+		}
+	}
+	if g.NightVoting != nil {
+		j.NightVoting = g.NightVoting.Name
+	}
+	if g.StartPlayers != nil {
+		for _, p := range *g.StartPlayers {
+			j.StartPlayers = append(j.StartPlayers, *p)
+		}
+	}
+	if g.Spectators != nil {
+		for _, p := range *g.Spectators {
+			j.Spectators = append(j.Spectators, *p)
+		}
+	}
+	if g.Active != nil {
+		for _, id := range g.Active.GetIDs() {
+			j.Active = append(j.Active, newPlayerJSON(*(*g.Active)[id]))
+		}
+	}
+	if g.Dead != nil {
+		for _, deadPlayers := range *g.Dead {
+			for _, p := range deadPlayers {
+				j.Dead = append(j.Dead, deadPlayerJSON{
+					playerJSON: newPlayerJSON(p.Player),
+					DeadReason: p.DeadReason,
+					LivedDays:  p.LivedDays,
+				})
+			}
+		}
+		// Map iteration order is random.
+		sort.Slice(j.Dead, func(i, k int) bool { return j.Dead[i].ID < j.Dead[k].ID })
+	}
+	return json.Marshal(j)
+}
+
+func (g *DeepCloneGame) UnmarshalJSON(data []byte) error {
+	var j deepCloneGameJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if j.Version != DeepCloneGameJSONVersion {
+		return fmt.Errorf("%w: %v", UnsupportedJSONVersionErr, j.Version)
+	}
+
+	decoded := DeepCloneGame{
+		GuildID:         j.GuildID,
+		PlayersCount:    j.PlayersCount,
+		NightCounter:    j.NightCounter,
+		TimeStart:       j.TimeStart,
+		EndTime:         j.EndTime,
+		MainChannelID:   j.MainChannelID,
+		RoleChannelIDs:  j.RoleChannelIDs,
+		NightVotesStart: j.NightVotesStart,
+		VoteForYourself: j.VoteForYourself,
+		VotePing:        j.VotePing,
+		PreviousState:   j.PreviousState,
+		State:           j.State,
+		RenameMode:      j.RenameMode,
+		NightLogs:       j.NightLogs,
+		DayLogs:         j.DayLogs,
+		Seed:            j.Seed,
+	}
+	if j.RolesConfig != nil {
+		decoded.RolesConfig = &configPack.RolesConfig{
+			PlayersCount: j.RolesConfig.PlayersCount,
+			RolesMp:      make(map[string]*configPack.RoleConfig),
+		}
+		for roleName, count := range j.RolesConfig.Roles {
+			role, ok := rolesPack.GetRoleByName(roleName)
+			if !ok {
+				return fmt.Errorf("%w: %v", UnknownRoleErr, roleName)
+			}
+			decoded.RolesConfig.RolesMp[roleName] = &configPack.RoleConfig{Role: role, Count: count}
+		}
+	}
+	if j.NightVoting != "" {
+		role, ok := rolesPack.GetRoleByName(j.NightVoting)
+		if !ok {
+			return fmt.Errorf("%w: %v", UnknownRoleErr, j.NightVoting)
+		}
+		decoded.NightVoting = role
+	}
+
+	startPlayers := playerPack.NonPlayingPlayers{}
+	for _, p := range j.StartPlayers {
+		startPlayer := p
+		startPlayers = append(startPlayers, &startPlayer)
+	}
+	spectators := playerPack.NonPlayingPlayers{}
+	for _, p := range j.Spectators {
+		spectator := p
+		spectators = append(spectators, &spectator)
+	}
+	active := make(playerPack.Players)
+	for _, p := range j.Active {
+		activePlayer, err := p.toPlayer()
+		if err != nil {
+			return err
+		}
+		active[activePlayer.ID] = activePlayer
+	}
+	dead := make(playerPack.DeadPlayers)
+	for _, p := range j.Dead {
+		deadPlayer, err := p.toPlayer()
+		if err != nil {
+			return err
+		}
+		dead.Add(playerPack.NewDeadPlayer(deadPlayer, p.DeadReason, p.LivedDays))
+	}
+	decoded.StartPlayers = &startPlayers
+	decoded.Spectators = &spectators
+	decoded.Active = &active
+	decoded.Dead = &dead
+
+	*g = decoded
+	return nil
 }
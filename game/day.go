@@ -1,168 +1,495 @@
 package game
 
 import (
-	"math"
+	"maps"
+	"sort"
 	"time"
 
 	"github.com/https-whoyan/MafiaCore/player"
+	timePack "github.com/https-whoyan/MafiaCore/time"
+	"github.com/samber/lo"
 )
 
 const (
 	DayPercentageToNextStage = 50
 )
 
+// DayProcedure presents how the players vote at the day.
+//
+// Adjustable by DayProcedureOpt.
+type DayProcedure uint8
+
+const (
+	// SimplePluralityDayProcedure one open vote for everyone.
+	// The player, who got DayPercentageToNextStage percent of votes, is kicked at once.
+	// Otherwise, the player with the most votes is kicked, tie means skip.
+	SimplePluralityDayProcedure DayProcedure = iota
+	// NominationDayProcedure nominations, defense speech of each nominee, the final vote
+	// between the nominees, and the runoff vote between the tied nominees.
+	NominationDayProcedure
+)
+
+// DayPhase presents the part of the day procedure.
+type DayPhase string
+
+const (
+	NoDayPhase          DayPhase = ""
+	OpenVotingDayPhase  DayPhase = "open voting"
+	NominationDayPhase  DayPhase = "nomination"
+	DefenseDayPhase     DayPhase = "defense"
+	FinalVotingDayPhase DayPhase = "final voting"
+	RunoffDayPhase      DayPhase = "runoff voting"
+)
+
+// IsVoting reports whether the players can vote (SetDayVote) in the phase.
+func (p DayPhase) IsVoting() bool {
+	switch p {
+	case OpenVotingDayPhase, NominationDayPhase, FinalVotingDayPhase, RunoffDayPhase:
+		return true
+	default:
+		return false
+	}
+}
+
 func (g *Game) Day() DayLog {
 	select {
 	case <-g.ctx.Done():
 		return DayLog{}
 	default:
 		g.SetState(DayState)
-		g.infoSender <- g.newSwitchStateSignal()
+		g.sendInfo(g.newSwitchStateSignal())
 
-		g.RLock()
-		deadline := CalculateDayDeadline(
-			g.nightCounter, g.dead.Len(), g.rolesConfig.PlayersCount)
-		g.RUnlock()
-		safeSendErrSignal(g.errSender, g.messenger.Day.SendMessageAboutNewDay(g.mainChannel, deadline))
+		var dayLog DayLog
+		switch g.dayProcedure {
+		case NominationDayProcedure:
+			dayLog = g.StartNominationDay()
+		default:
+			g.RLock()
+			deadline := g.timings.DayDeadline(
+				g.nightCounter, g.dead.Len(), g.rolesConfig.PlayersCount)
+			g.RUnlock()
+			safeSendErrSignal(g.errSender, g.messenger.Day.SendMessageAboutNewDay(g.mainChannel, deadline))
 
-		return g.StartDayVoting(deadline)
+			dayLog = g.StartDayVoting(deadline)
+		}
+		return dayLog
 	}
 }
 
 func (g *Game) StartDayVoting(deadline time.Duration) DayLog {
-	votesMp := make(map[player.IDType]player.IDType)
-	occurrencesMp := make(map[player.IDType]int)
-
-	g.timer(deadline)
+	g.RLock()
+	rules := g.dayVotingRules
+	activeCount := g.active.Len()
+	g.RUnlock()
 
-	var kickedPlayerID player.IDType = EmptyVoteInt
-	var breakDownDayPlayersCount = int(math.Ceil(float64(DayPercentageToNextStage*g.active.Len()) / 100.0))
+	votes := g.collectDayVotes(OpenVotingDayPhase, nil, deadline, func(votes map[player.IDType]player.IDType) bool {
+		return rules.isDecided(votes, activeCount)
+	})
 
-	acceptTheVote := func(voteP DayVoteProviderInterface) (kickedID *player.IDType, isEndVoting bool) {
-		var (
-			voter, toVoter, isEmpty = g.oneVoteHelper(voteP)
-			votedPlayerID           = voter.ID
-			vote                    player.IDType
-		)
+	dayLog := DayLog{
+		DayNumber: g.nightCounter,
+		DayVotes:  votes,
+		IsSkip:    true,
+		Rules:     rules,
+	}
+	leaders := rules.getLeaders(votes, activeCount)
+	switch {
+	case len(leaders) == 1:
+		dayLog.kick(leaders...)
+	case len(leaders) > 1:
+		dayLog.Tied = leaders
+		g.breakDayTie(&dayLog, leaders)
+	}
+	return dayLog
+}
 
-		if isEmpty {
-			vote = EmptyVoteInt
-		} else {
-			vote = toVoter.ID
+// breakDayTie kicks the tied players (or no one) by the TieBreak of the rules.
+func (g *Game) breakDayTie(dayLog *DayLog, tied []player.IDType) {
+	switch dayLog.Rules.TieBreak {
+	case RunoffDayTieBreak:
+		if g.isInterrupted() {
+			return
 		}
-
-		if prevVote, isContains := votesMp[votedPlayerID]; isContains {
-			occurrencesMp[prevVote]--
+		g.RLock()
+		votingDeadline := g.timings.FinalVotingDeadline
+		g.RUnlock()
+		safeSendErrSignal(g.errSender,
+			g.messenger.Day.SendFinalVotingMessage(g.mainChannel, tied, true, votingDeadline))
+		dayLog.Runoff = tied
+		dayLog.RunoffVotes = g.collectDayVotes(RunoffDayPhase, tied, votingDeadline, nil)
+		g.RLock()
+		activeCount := g.active.Len()
+		g.RUnlock()
+		if leaders := dayLog.Rules.getLeaders(dayLog.RunoffVotes, activeCount); len(leaders) == 1 {
+			dayLog.kick(leaders...)
 		}
-		occurrencesMp[vote]++
-		votesMp[votedPlayerID] = vote
+	case RandomDayTieBreak:
+		dayLog.kick(tied[g.rand.Intn(len(tied))])
+	case KillAllDayTieBreak:
+		dayLog.kick(tied...)
+	}
+}
 
-		// If occurrencesMp[vote] >= breakDownDayPlayersCount
-		if occurrencesMp[vote] >= breakDownDayPlayersCount {
-			kickedID = &vote
-			return kickedID, true
-		}
-		// Case, when all players leave his vote
-		if len(votesMp) == g.active.Len() {
-			// Calculate pVote, which have maximum occurrences
-			var (
-				mxOccurrence               = 0
-				mxVote       player.IDType = 0
-			)
-
-			for pVote, occurrence := range occurrencesMp {
-				if occurrence > mxOccurrence {
-					mxOccurrence = occurrence
-					mxVote = pVote
-				} else if occurrence == mxOccurrence {
-					mxVote = EmptyVoteInt
-				}
-			}
+// ____________________
+// Day voting rules
+// ____________________
 
-			if mxVote == EmptyVoteInt {
-				return nil, true
-			}
-			kickedID = &mxVote
-			return kickedID, true
+// DayMajority presents how many votes the player needs to be kicked.
+type DayMajority uint8
+
+const (
+	// SimpleDayMajority the player with the most votes is kicked.
+	// DayPercentageToNextStage percent of votes ends the voting at once.
+	SimpleDayMajority DayMajority = iota
+	// AbsoluteDayMajority the player needs more than half of the votes.
+	AbsoluteDayMajority
+	// TwoThirdsDayMajority the player needs two-thirds of the votes.
+	TwoThirdsDayMajority
+)
+
+// DayTieBreak presents what happens, if several players got the most votes.
+type DayTieBreak uint8
+
+const (
+	// SkipDayTieBreak no one is kicked.
+	SkipDayTieBreak DayTieBreak = iota
+	// RunoffDayTieBreak one more vote between the tied players. A tie again means skip.
+	RunoffDayTieBreak
+	// RandomDayTieBreak one of the tied players is kicked at random.
+	RandomDayTieBreak
+	// KillAllDayTieBreak all the tied players are kicked.
+	KillAllDayTieBreak
+)
+
+// DayVotingRules presents the rules of the day voting (of the final vote and the runoff for NominationDayProcedure).
+//
+// The zero value is the default rules: simple majority, empty votes are counted, tie means skip.
+//
+// Adjustable by DayVotingRulesOpt.
+type DayVotingRules struct {
+	Majority DayMajority `json:"majority"`
+	// IgnoreEmptyVotes if true, the majority is calculated only from the votes for the players,
+	// and the empty votes can not skip the day.
+	// Otherwise, all active players are counted, and the empty votes compete as one more candidate.
+	IgnoreEmptyVotes bool        `json:"ignoreEmptyVotes"`
+	TieBreak         DayTieBreak `json:"tieBreak"`
+}
+
+// denominator returns the number of votes, from which the majority is calculated.
+func (r DayVotingRules) denominator(votes map[player.IDType]player.IDType, activeCount int) int {
+	if !r.IgnoreEmptyVotes {
+		return activeCount
+	}
+	return lo.CountBy(lo.Values(votes), func(vote player.IDType) bool { return vote != EmptyVoteInt })
+}
+
+// isReached reports whether count votes of denominator is enough to be kicked.
+func (r DayVotingRules) isReached(count, denominator int) bool {
+	if count == 0 {
+		return false
+	}
+	switch r.Majority {
+	case AbsoluteDayMajority:
+		return 2*count > denominator
+	case TwoThirdsDayMajority:
+		return 3*count >= 2*denominator
+	default:
+		return 100*count >= DayPercentageToNextStage*denominator
+	}
+}
+
+// isDecided reports whether the voting can be ended before all players vote.
+//
+// The majority of all active players is the majority of any part of them.
+func (r DayVotingRules) isDecided(votes map[player.IDType]player.IDType, activeCount int) bool {
+	occurrences := lo.CountValues(lo.Values(votes))
+	for vote, count := range occurrences {
+		if vote == EmptyVoteInt && r.IgnoreEmptyVotes {
+			continue
+		}
+		if r.isReached(count, activeCount) {
+			return true
 		}
+	}
+	return false
+}
 
-		return
+// getLeaders returns the sorted players, who can be kicked by the votes.
+// Several players means a tie. Empty means skip.
+func (r DayVotingRules) getLeaders(votes map[player.IDType]player.IDType, activeCount int) []player.IDType {
+	leaders := getDayVotesLeaders(votes)
+	if len(leaders) == 0 {
+		return nil
 	}
+	occurrences := lo.CountValues(lo.Values(votes))
+	leaderCount := occurrences[leaders[0]]
+	// Empty votes compete with the leaders, tie with them means skip.
+	if !r.IgnoreEmptyVotes && occurrences[EmptyVoteInt] >= leaderCount {
+		return nil
+	}
+	if r.Majority != SimpleDayMajority && !r.isReached(leaderCount, r.denominator(votes, activeCount)) {
+		return nil
+	}
+	return leaders
+}
+
+// ____________________
+// Nomination day
+// ____________________
+
+// StartNominationDay plays the day with NominationDayProcedure.
+//
+// The final vote and the runoff are counted by DayVotingRules, the TieBreak of the rules is not used:
+// the tie of the final vote is always broken by the runoff.
+func (g *Game) StartNominationDay() DayLog {
+	g.RLock()
+	rules := g.dayVotingRules
+	activeCount := g.active.Len()
+	nominationDeadline := g.timings.NominationDeadline
+	g.RUnlock()
 
 	dayLog := DayLog{
 		DayNumber: g.nightCounter,
-		IsSkip:    false,
+		DayVotes:  make(map[player.IDType]player.IDType),
+		IsSkip:    true,
+		Rules:     rules,
+	}
+
+	// Nominations. The vote for the player is his nomination.
+	safeSendErrSignal(g.errSender, g.messenger.Day.SendNominationMessage(g.mainChannel, nominationDeadline))
+	nominations := g.collectDayVotes(NominationDayPhase, nil, nominationDeadline, nil)
+
+	nominees := lo.Uniq(lo.Filter(lo.Values(nominations), func(id player.IDType, _ int) bool {
+		return id != EmptyVoteInt
+	}))
+	sort.Slice(nominees, func(i, j int) bool { return nominees[i] < nominees[j] })
+	dayLog.Nominees = nominees
+	if len(nominees) == 0 || g.isInterrupted() {
+		return dayLog
+	}
+
+	// Defense and the final vote.
+	g.defendCandidates(nominees)
+	if g.isInterrupted() {
+		return dayLog
+	}
+	g.RLock()
+	votingDeadline := g.timings.FinalVotingDeadline
+	g.RUnlock()
+	safeSendErrSignal(g.errSender,
+		g.messenger.Day.SendFinalVotingMessage(g.mainChannel, nominees, false, votingDeadline))
+	dayLog.DayVotes = g.collectDayVotes(FinalVotingDayPhase, nominees, votingDeadline, nil)
+
+	leaders := rules.getLeaders(dayLog.DayVotes, activeCount)
+	switch {
+	case len(leaders) == 1:
+		dayLog.kick(leaders...)
+		return dayLog
+	case len(leaders) == 0 || g.isInterrupted():
+		return dayLog
+	}
+
+	// Runoff between the tied nominees, after their defense.
+	dayLog.Tied = leaders
+	g.defendCandidates(leaders)
+	if g.isInterrupted() {
+		return dayLog
 	}
+	safeSendErrSignal(g.errSender,
+		g.messenger.Day.SendFinalVotingMessage(g.mainChannel, leaders, true, votingDeadline))
+	dayLog.Runoff = leaders
+	dayLog.RunoffVotes = g.collectDayVotes(RunoffDayPhase, leaders, votingDeadline, nil)
+	if runoffLeaders := rules.getLeaders(dayLog.RunoffVotes, activeCount); len(runoffLeaders) == 1 {
+		dayLog.kick(runoffLeaders...)
+	}
+	return dayLog
+}
+
+// defendCandidates gives each candidate the time to the defense speech.
+func (g *Game) defendCandidates(candidates []player.IDType) {
+	g.RLock()
+	deadline := g.timings.DefenseDeadline
+	g.RUnlock()
+
+	for _, candidateID := range candidates {
+		speaker := candidateID
+		g.switchDayPhase(DefenseDayPhase, candidates, &speaker)
+
+		g.RLock()
+		candidate := g.active.GetByIDType(candidateID)
+		g.RUnlock()
+		if candidate != nil {
+			safeSendErrSignal(g.errSender, g.messenger.Day.SendDefenseMessage(g.mainChannel, candidate, deadline))
+		}
 
-	standDayLog := func(kickedID *player.IDType) {
-		dayLog.Kicked = kickedID
-		dayLog.DayVotes = votesMp
-		dayLog.IsSkip = false
-		if kickedID == nil || *kickedID == EmptyVoteInt {
-			dayLog.Kicked = nil
-			dayLog.IsSkip = true
+		timer := g.newPhaseTimer(deadline)
+		select {
+		case <-timer.C():
+		case <-g.ctx.Done():
+		}
+		g.stopPhaseTimer(timer)
+		if g.isInterrupted() {
+			return
 		}
 	}
+}
+
+// collectDayVotes opens the day voting and waits for the votes of all active players, or for the deadline.
+// If isDecided is not nil, the voting ends as soon as it returns true.
+//
+// Key - voter ID, value - vote.
+func (g *Game) collectDayVotes(phase DayPhase, candidates []player.IDType, deadline time.Duration,
+	isDecided func(votes map[player.IDType]player.IDType) bool) map[player.IDType]player.IDType {
+	g.ClearDayVotes()
+
+	// The timer starts after the signal, so the deadline is counted from the opening of the voting.
+	g.switchDayPhase(phase, candidates, nil)
+	g.RLock()
+	voted := g.dayVoteChan
+	g.RUnlock()
+
+	timer := g.newPhaseTimer(deadline)
+	defer g.stopPhaseTimer(timer)
 
 	for {
-		isNeedToContinue := true
 		select {
 		case <-g.ctx.Done():
-			break
-		case <-g.timerDone:
-			isNeedToContinue = false
-			break
-		case voteP := <-g.dayVoteChan:
-			maybeKickedID, isEnd := acceptTheVote(voteP)
-			g.infoLogger.Println(maybeKickedID, isEnd)
-			if isEnd {
-				if maybeKickedID != nil {
-					kickedPlayerID = *maybeKickedID
-				} else {
-					kickedPlayerID = EmptyVoteInt
-					kickedPlayerID = EmptyVoteInt
+			return g.closeDayVoting()
+		case <-timer.C():
+			// The votes, accepted before the deadline, may decide the voting.
+			if g.isSkipped(timer) || g.isDayVotingDecided(isDecided) {
+				return g.closeDayVoting()
+			}
+			g.timerExpired()
+			votes := g.closeDayVoting()
+			// Not to nominate anyone is the choice of the player.
+			if phase != NominationDayPhase {
+				g.Lock()
+				for _, id := range g.active.GetIDs() {
+					if _, ok := votes[id]; !ok {
+						g.markMissed(id)
+					}
 				}
-				isNeedToContinue = false
-				g.timerStop <- struct{}{}
-				break
+				g.Unlock()
+			}
+			return votes
+		case <-voted:
+			if g.isDayVotingDecided(isDecided) {
+				return g.closeDayVoting()
 			}
-		}
-		if !isNeedToContinue {
-			break
 		}
 	}
+}
 
-	standDayLog(&kickedPlayerID)
-	return dayLog
+// isDayVotingDecided reports whether all active players have voted, or isDecided returns true.
+func (g *Game) isDayVotingDecided(isDecided func(votes map[player.IDType]player.IDType) bool) bool {
+	g.RLock()
+	votes := maps.Clone(g.dayVotes)
+	activeCount := g.active.Len()
+	g.RUnlock()
+	return len(votes) == activeCount || (isDecided != nil && isDecided(votes))
 }
 
-// CalculateDayDeadline calculate the day max time.
-func CalculateDayDeadline(nighCounter int, deadCount int, totalPlayers int) time.Duration {
-	// Weights of aspects
-	const (
-		currNightCounterWeight  = 0.61
-		deadCountWeight         = 0.68
-		totalPlayersCountWeight = 0.27
+// getDayVotesLeaders returns the sorted candidates with the most votes.
+// Empty votes are ignored.
+func getDayVotesLeaders(votes map[player.IDType]player.IDType) []player.IDType {
+	occurrences := make(map[player.IDType]int)
+	for _, vote := range votes {
+		if vote != EmptyVoteInt {
+			occurrences[vote]++
+		}
+	}
+	var (
+		leaders       []player.IDType
+		mxOccurrences int
 	)
+	for candidate, occurrence := range occurrences {
+		switch {
+		case occurrence > mxOccurrences:
+			mxOccurrences = occurrence
+			leaders = []player.IDType{candidate}
+		case occurrence == mxOccurrences:
+			leaders = append(leaders, candidate)
+		}
+	}
+	sort.Slice(leaders, func(i, j int) bool { return leaders[i] < leaders[j] })
+	return leaders
+}
 
-	var basicMinutes = 0.0 // TODO 2.2
-	nightCounterAddMinutes := currNightCounterWeight * float64(nighCounter)
-	deadCountAddMinutes := deadCountWeight * float64(deadCount)
-	totalPlayersCountAddMinutes := totalPlayersCountWeight * float64(totalPlayers)
+func (g *Game) isInterrupted() bool {
+	select {
+	case <-g.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
 
-	totalTime := basicMinutes + nightCounterAddMinutes + deadCountAddMinutes + totalPlayersCountAddMinutes
-	totalTimeMinutes := math.Ceil(totalTime)
-	return time.Minute * time.Duration(totalTimeMinutes)
+// ____________________
+// Day phases
+// ____________________
+
+// switchDayPhase sets the phase of the day and informs about it.
+//
+// If the phase is voting, SetDayVote is available until closeDayVoting.
+func (g *Game) switchDayPhase(phase DayPhase, candidates []player.IDType, speaker *player.IDType) {
+	g.Lock()
+	g.dayPhase = phase
+	g.dayCandidates = candidates
+	if phase.IsVoting() {
+		g.dayVotingDone = make(chan struct{})
+		g.dayVotes = make(map[player.IDType]player.IDType)
+		g.dayVoteChan = make(chan struct{}, 1)
+		g.inviteBotsToDayVote()
+	}
+	g.record(DayPhaseSwitchedEvent, DayPhaseSwitchedData{
+		Phase:      phase,
+		Candidates: candidates,
+		Speaker:    speaker,
+	})
+	signal := g.newSwitchDayPhaseSignal(speaker)
+	g.Unlock()
+	g.sendInfo(signal)
+}
+
+// closeDayVoting releases all SetDayVote that are waiting for the end of the voting,
+// and returns the votes of the voting.
+func (g *Game) closeDayVoting() map[player.IDType]player.IDType {
+	g.Lock()
+	defer g.Unlock()
+	close(g.dayVotingDone)
+	votes := g.dayVotes
+	g.dayVotes = nil
+	g.dayPhase = NoDayPhase
+	g.dayCandidates = nil
+	return votes
+}
+
+// CalculateDayDeadline calculate the day max time with default weights.
+//
+// See timePack.Timings DayDeadline to use your own formula.
+func CalculateDayDeadline(nighCounter int, deadCount int, totalPlayers int) time.Duration {
+	return timePack.CalculateDayDeadline(nighCounter, deadCount, totalPlayers)
 }
 
 func (g *Game) AffectDay(l DayLog) (isFool bool) {
+	defer g.sendInfo(g.newInfoSignal(DayResultSignal, DayResultInfo{Log: l}))
 	if l.IsSkip {
 		safeSendErrSignal(g.errSender, g.messenger.Day.SendMessageThatDayIsSkipped(g.mainChannel))
 		return
 	}
-	kickedPlayer := (*g.active)[*l.Kicked]
-	safeSendErrSignal(g.errSender, g.messenger.Day.SendMessageAboutKickedPlayer(g.mainChannel, kickedPlayer))
+	for _, kickedID := range l.GetKicked() {
+		kickedPlayer := (*g.active)[kickedID]
+		safeSendErrSignal(g.errSender, g.messenger.Day.SendMessageAboutKickedPlayer(g.mainChannel, kickedPlayer))
 
-	g.active.ToDead(kickedPlayer.ID, player.KilledByDayVoting, g.nightCounter, g.dead)
+		g.active.ToDead(kickedPlayer.ID, player.KilledByDayVoting, g.nightCounter, g.dead)
+		died := PlayerDiedData{
+			PlayerID:  kickedPlayer.ID,
+			Reason:    player.KilledByDayVoting,
+			LivedDays: g.nightCounter,
+		}
+		g.record(PlayerDiedEvent, died)
+		g.sendPlayerDied(died)
+	}
 	return
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/hashicorp/go-multierror"
 	"github.com/https-whoyan/MafiaCore/log"
+	"math/rand"
 	"os"
 	"sync"
 	"time"
@@ -14,6 +15,7 @@ import (
 	configPack "github.com/https-whoyan/MafiaCore/config"
 	fmtPack "github.com/https-whoyan/MafiaCore/fmt"
 	playerPack "github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/registry"
 	rolesPack "github.com/https-whoyan/MafiaCore/roles"
 	timePack "github.com/https-whoyan/MafiaCore/time"
 )
@@ -70,6 +72,53 @@ func ErrLoggerOpt(logger log.Logger) Option {
 func VoteForYourselfOpt(voteForYourself bool) Option {
 	return func(g *Game) { g.voteForYourself = voteForYourself }
 }
+func TimingsOpt(timings timePack.Timings) Option {
+	return func(g *Game) { g.timings = timings.WithDefaults() }
+}
+func ClockOpt(clock timePack.Clock) Option {
+	return func(g *Game) { g.clock = clock }
+}
+func DayProcedureOpt(procedure DayProcedure) Option {
+	return func(g *Game) { g.dayProcedure = procedure }
+}
+func DayVotingRulesOpt(rules DayVotingRules) Option {
+	return func(g *Game) { g.dayVotingRules = rules }
+}
+
+// ValidateConfigOpt makes Init reject the config with any problem of RolesConfig.Validate,
+// including the team, which has already won at the start (with the house rules of the game).
+// Balance warnings of RolesConfig.Lint are only logged.
+func ValidateConfigOpt() Option {
+	return func(g *Game) { g.validateConfig = true }
+}
+
+// TeamWinConditionOpt replaces the win condition of the team only in this game (house rules).
+// For example, "Maniac wins 1v1":
+//
+//	TeamWinConditionOpt(rolesPack.ManiacTeam, registry.TeamParity(rolesPack.ManiacTeam))
+//
+// Teams with the conditions from this option are checked before the others, in order of the options.
+// nil condition means that the team can not win in this game.
+func TeamWinConditionOpt(team rolesPack.Team, condition registry.WinCondition) Option {
+	return func(g *Game) {
+		if _, ok := g.teamWinConditions[team]; !ok {
+			g.teamWinOrder = append(g.teamWinOrder, team)
+		}
+		g.teamWinConditions[team] = condition
+	}
+}
+
+// SoloWinConditionOpt replaces the condition under which the role wins on its own only in this game.
+// Use nil condition, if the role must play only for its team.
+func SoloWinConditionOpt(role *rolesPack.Role, condition registry.WinCondition) Option {
+	return func(g *Game) { g.soloWinConditions[role] = condition }
+}
+
+// RandSeedOpt sets the seed of all randomness of the game.
+// Use the seed from DeepCloneGame to reproduce the game.
+func RandSeedOpt(seed int64) Option {
+	return func(g *Game) { g.seed = seed }
+}
 
 // __________________
 // Game struct
@@ -102,6 +151,22 @@ type Game struct {
 	// key: str - role name
 	roleChannels map[*rolesPack.Role]channelPack.RoleChannel
 	mainChannel  channelPack.MainChannel
+	// Bots of the players, key - tag of the player. See BotPlayer.
+	bots map[string]BotPlayer
+	// The left players, see afk.go.
+	//
+	// afkPhases and afkPolicy are adjustable by AFKOpt.
+	afkPhases    int
+	afkPolicy    AFKPolicy
+	missedPhases map[playerPack.IDType]int
+	disconnected map[playerPack.IDType]bool
+	notifiedAFK  map[playerPack.IDType]bool
+	// Value - the reason of the death (player.Left, or the reason of ForceKill).
+	leaving map[playerPack.IDType]playerPack.DeadReason
+	// The host controls, see host.go.
+	isPaused     bool
+	currentTimer *phaseTimer
+	reviving     map[playerPack.IDType]bool
 
 	// Keeps what role is voting (in night) right now.
 	nightVoting *rolesPack.Role
@@ -109,10 +174,44 @@ type Game struct {
 	nightLogs []NightLog
 	dayLogs   []DayLog
 
-	voteAccepted chan struct{}
-	dayVoteChan  chan DayVoteProviderInterface
+	// Turns of the roles in the current night, see nightTurn.
+	nightTurns map[*rolesPack.Role]*nightTurn
+	// Key - player ID, value - count of his votes at the start of the current night.
+	// The unfinished night is rolled back to it (see Restore).
+	nightVotesStart map[playerPack.IDType]int
+	// How the roles decide their night votes.
+	//
+	// Adjustable by option.
+	teamVotePolicies map[*rolesPack.Role]TeamVotePolicy
+	// Night targeting rules of the roles, replacing rolesPack.TargetRules.
+	//
+	// Adjustable by option.
+	targetRules map[*rolesPack.Role]rolesPack.TargetRules
+	// Gets the notification of the accepted vote of the current day voting.
+	dayVoteChan chan struct{}
+	// How the players vote at the day.
+	//
+	// Default value: SimplePluralityDayProcedure.
+	//
+	// Adjustable by option.
+	dayProcedure DayProcedure
+	// Majority, empty votes and tie-break of SimplePluralityDayProcedure.
+	//
+	// Adjustable by option.
+	dayVotingRules DayVotingRules
+	// Keeps the phase of the day procedure and the players, who can be voted for (nil means anyone).
+	dayPhase      DayPhase
+	dayCandidates []playerPack.IDType
+	// Closed, when the current day voting is over.
+	dayVotingDone chan struct{}
+	// Key - voter ID, value - vote. nil, if there is no day voting now.
+	dayVotes map[playerPack.IDType]playerPack.IDType
 	// Can the player choose himself
 	voteForYourself bool
+	// Check the config with RolesConfig.Validate at Init.
+	//
+	// Adjustable by option.
+	validateConfig bool
 	// votePing presents a delay number for voting for the same player again.
 	//
 	// Example: A player has voted for players with IDs 5, 4, 3, and votePing is 2.
@@ -123,8 +222,33 @@ type Game struct {
 	// Adjustable by option.
 	votePing int
 
-	timerDone chan struct{}
-	timerStop chan struct{}
+	// All durations of the game.
+	//
+	// Adjustable by option.
+	timings timePack.Timings
+	// Source of the time for all timers of the game.
+	//
+	// Default value: timePack.RealClock.
+	//
+	// Adjustable by option.
+	clock timePack.Clock
+	// seed of the rand.
+	//
+	// Default value: the time of the game creation.
+	//
+	// Adjustable by option.
+	seed int64
+	rand *rand.Rand
+	// Journal of all state-changing actions of the game. See Event.
+	journal *journal
+	// House rules of the game.
+	// See TeamWinConditionOpt and SoloWinConditionOpt.
+	teamWinConditions map[rolesPack.Team]registry.WinCondition
+	teamWinOrder      []rolesPack.Team
+	soloWinConditions map[*rolesPack.Role]registry.WinCondition
+	// Not empty, if the game was restored.
+	// Presents the phase, from which the game continues. See Restore.
+	restoredPhase State
 
 	previousState State
 	state         State
@@ -136,6 +260,7 @@ type Game struct {
 	infoSender     chan<- InfoSignal
 	errChanDest    <-chan ErrSignal
 	infoChanDest   <-chan InfoSignal
+	infoQueue      *infoQueue
 	finishFuncOnce *sync.Once
 	finishOnce     *sync.Once
 	storage        Storage
@@ -152,11 +277,6 @@ func GetNewGame(ctx context.Context, guildID string, opts ...Option) *Game {
 	newGame := &Game{
 		guildID: guildID,
 		state:   NonDefinedState,
-		// Chan s create.
-		voteAccepted: make(chan struct{}),
-		timerDone:    make(chan struct{}),
-		timerStop:    make(chan struct{}),
-		dayVoteChan:  make(chan DayVoteProviderInterface),
 		// Slices.
 		startPlayers: &start,
 		active:       &active,
@@ -167,14 +287,31 @@ func GetNewGame(ctx context.Context, guildID string, opts ...Option) *Game {
 		infoLogger:   logger,
 		// Create a map
 		roleChannels:   make(map[*rolesPack.Role]channelPack.RoleChannel),
+		nightTurns:     make(map[*rolesPack.Role]*nightTurn),
+		bots:           make(map[string]BotPlayer),
+		missedPhases:   make(map[playerPack.IDType]int),
+		disconnected:   make(map[playerPack.IDType]bool),
+		notifiedAFK:    make(map[playerPack.IDType]bool),
+		leaving:        make(map[playerPack.IDType]playerPack.DeadReason),
+		reviving:       make(map[playerPack.IDType]bool),
 		votePing:       1,
+		timings:        timePack.DefaultTimings(),
+		clock:          timePack.RealClock,
+		seed:           time.Now().UnixNano(),
 		errSender:      errChan,
 		infoSender:     infoChan,
 		errChanDest:    errChan,
 		infoChanDest:   infoChan,
 		finishFuncOnce: &sync.Once{},
 		finishOnce:     &sync.Once{},
-		ctx:            ctx,
+		journal:        newJournal(),
+		infoQueue:      &infoQueue{},
+		// House rules
+		teamWinConditions: make(map[rolesPack.Team]registry.WinCondition),
+		teamVotePolicies:  make(map[*rolesPack.Role]TeamVotePolicy),
+		targetRules:       make(map[*rolesPack.Role]rolesPack.TargetRules),
+		soloWinConditions: make(map[*rolesPack.Role]registry.WinCondition),
+		ctx:               ctx,
 	}
 	messanger := NewGameMessanger(fmtPack.NilFMTInterfaceInstance, newGame)
 	newGame.messenger = messanger
@@ -182,6 +319,7 @@ func GetNewGame(ctx context.Context, guildID string, opts ...Option) *Game {
 	for _, opt := range opts {
 		opt(newGame)
 	}
+	newGame.rand = newRand(newGame.seed)
 	return newGame
 }
 
@@ -206,6 +344,7 @@ func GetNewGame(ctx context.Context, guildID string, opts ...Option) *Game {
 // Init validation Errors.
 var (
 	EmptyConfigErr                             = errors.New("empty config")
+	InvalidConfigErr                           = errors.New("invalid config")
 	MismatchPlayersCountAndGamePlayersCountErr = errors.New("mismatch config playersCount and game players")
 	NotFullRoleChannelInfoErr                  = errors.New("not full role channel info")
 	NotMainChannelInfoErr                      = errors.New("not main channel info")
@@ -228,8 +367,27 @@ func (g *Game) validationStart(cfg *configPack.RolesConfig) error {
 	if cfg.PlayersCount != len(*(g.startPlayers)) {
 		err = multierror.Append(err, MismatchPlayersCountAndGamePlayersCountErr)
 	}
-	if len(g.roleChannels) != len(rolesPack.GetAllNightInteractionRolesNames()) {
-		err = multierror.Append(err, NotFullRoleChannelInfoErr)
+	if botsErr := g.validateBots(); botsErr != nil {
+		err = multierror.Append(err, botsErr)
+	}
+	if g.validateConfig {
+		winner := registry.ConfigWinner(g.teamWinOrder, g.teamWinConditions)
+		problems := cfg.Validate(configPack.ValidateWinnerOpt(winner))
+		for _, problem := range problems {
+			err = multierror.Append(err, fmt.Errorf("%w: %w", InvalidConfigErr, problem))
+		}
+		// The other checks need the correct roles of the config.
+		if len(problems) != 0 {
+			return err
+		}
+		for _, warning := range cfg.Lint() {
+			g.infoLogger.Println("Config warning:", warning.Error())
+		}
+	}
+	for _, nightRole := range cfg.GetOrderToVote() {
+		if _, ok := g.roleChannels[nightRole]; !ok {
+			err = multierror.Append(err, fmt.Errorf("%w: %v", NotFullRoleChannelInfoErr, nightRole.Name))
+		}
 	}
 	if g.mainChannel == nil {
 		err = multierror.Append(err, NotMainChannelInfoErr)
@@ -285,20 +443,21 @@ func (g *Game) Init(cfg *configPack.RolesConfig) (err error) {
 	g.Lock()
 	g.rolesConfig = cfg
 	g.playersCount = cfg.PlayersCount
-	g.timeStart = time.Now()
+	g.timeStart = g.clock.Now()
 	g.Unlock()
 
 	// Get Players
 	tags := g.startPlayers.GetTags()
 	oldNicknames := g.startPlayers.GetUsernames()
 	serverUsernames := g.startPlayers.GetServerNicknames()
-	players, err := playerPack.GeneratePlayers(tags, oldNicknames, serverUsernames, cfg)
+	players, err := playerPack.GeneratePlayersWithRand(g.rand, tags, oldNicknames, serverUsernames, cfg)
 	if err != nil {
 		return err
 	}
 	// And state it to active field
 	g.Lock()
 	g.active = &players
+	g.markBots()
 	g.Unlock()
 
 	g.RLock()
@@ -400,6 +559,7 @@ func (g *Game) Init(cfg *configPack.RolesConfig) (err error) {
 	default:
 		return errors.New("invalid rename mode")
 	}
+	g.record(GameStartedEvent, g.newGameStartedData())
 	if g.storage != nil {
 		deepClone, deepCloneErr := g.GetDeepClone()
 		if deepCloneErr != nil {
@@ -439,11 +599,15 @@ Return receive chan of Signal type, that informed you about Signal's
 */
 func (g *Game) Run(ctx context.Context) (<-chan ErrSignal, <-chan InfoSignal) {
 	go func() {
-		// Send InteractionMessage About New Game
-		err := g.messenger.Init.SendStartMessage(g.mainChannel)
-		// Used for participants to familiarize themselves with their roles, and so on.
-		time.Sleep(timePack.RoleInfoCount * time.Second)
-		safeSendErrSignal(g.errSender, err)
+		var err error
+		// The players of the restored game already know their roles.
+		if g.restoredPhase == "" {
+			// Send InteractionMessage About New Game
+			err = g.messenger.Init.SendStartMessage(g.mainChannel)
+			// Used for participants to familiarize themselves with their roles, and so on.
+			g.clock.Sleep(g.timings.RoleInfoPause)
+			safeSendErrSignal(g.errSender, err)
+		}
 		switch {
 		case ctx == nil:
 			sendFatalSignal(g.errSender, NilContext)
@@ -453,6 +617,7 @@ func (g *Game) Run(ctx context.Context) (<-chan ErrSignal, <-chan InfoSignal) {
 			g.Lock()
 			g.ctx = ctx
 			g.Unlock()
+			g.initBots()
 
 			var (
 				finishLog      *FinishLog
@@ -489,76 +654,95 @@ func (g *Game) run() (isStoppedByCtx bool, finishLog *FinishLog) {
 	// FinishState will be set when the winner is already clear.
 	// This will be determined after the night and after the day's voting.
 
-	for g.state != FinishState {
-		isNeedToContinue := true
+	phase := State(NightState)
+	if g.restoredPhase != "" {
+		phase = g.restoredPhase
+		// The game could be saved right before its end.
+		if finishLog = g.understandFinishLog(); finishLog != nil {
+			g.ClearDayVotes()
+			return
+		}
+	}
+
+	for !g.IsFinished() {
 		select {
 		case <-g.ctx.Done():
-			isStoppedByCtx = true
-			isNeedToContinue = false
 			return true, nil
 		default:
-			g.Lock()
-			g.nightCounter++
-			g.Unlock()
-
-			// Night
+		}
 
-			nightLog := g.Night()
-			g.nightLogs = append(g.nightLogs, nightLog)
-			g.AffectNight(nightLog)
-			if g.storage != nil {
-				deepClone, deepCloneErr := g.GetDeepClone()
-				safeSendErrSignal(g.errSender, deepCloneErr)
-				err := g.storage.SaveNightLog(g.ctx, deepClone, nightLog)
-				safeSendErrSignal(g.errSender, err)
-			}
+		switch phase {
+		case NightState:
+			finishLog = g.playNight()
+			phase = DayState
+		case DayState:
+			finishLog = g.playDay()
+			phase = NightState
+		}
+		if finishLog != nil {
+			break
+		}
+		if phase == NightState {
+			g.ClearDayVotes()
+		}
+	}
+	g.ClearDayVotes()
+	return
+}
 
-			// Validate is final?
+// playNight plays the next night and returns not nil FinishLog, if the game is over after it.
+func (g *Game) playNight() *FinishLog {
+	g.Lock()
+	g.nightCounter++
+	g.Unlock()
 
-			winnerTeam := g.UnderstandWinnerTeam()
-			if winnerTeam != nil {
-				finishLogValue := g.NewFinishLog(winnerTeam, false)
-				finishLog = &finishLogValue
-				isNeedToContinue = false
-				break
-			}
+	nightLog := g.Night()
+	g.nightLogs = append(g.nightLogs, nightLog)
+	g.record(NightFinishedEvent, NightFinishedData{Log: nightLog})
+	g.AffectNight(nightLog)
+	if g.storage != nil {
+		deepClone, deepCloneErr := g.GetDeepClone()
+		safeSendErrSignal(g.errSender, deepCloneErr)
+		err := g.storage.SaveNightLog(g.ctx, deepClone, nightLog)
+		safeSendErrSignal(g.errSender, err)
+	}
+	g.LastWords(nightLog.Dead)
+	g.handleAFK()
+	g.handleRevives()
 
-			// Day
+	return g.understandFinishLog()
+}
 
-			dayLog := g.Day()
-			g.dayLogs = append(g.dayLogs, dayLog)
-			g.AffectDay(dayLog)
-			if g.storage != nil {
-				deepClone, deepCloneErr := g.GetDeepClone()
-				safeSendErrSignal(g.errSender, deepCloneErr)
-				err := g.storage.SaveDayLog(g.ctx, deepClone, dayLog)
-				safeSendErrSignal(g.errSender, err)
-			}
+// playDay same as playNight, but for the day.
+func (g *Game) playDay() *FinishLog {
+	dayLog := g.Day()
+	g.dayLogs = append(g.dayLogs, dayLog)
+	g.record(DayFinishedEvent, DayFinishedData{Log: dayLog})
+	g.AffectDay(dayLog)
+	if g.storage != nil {
+		deepClone, deepCloneErr := g.GetDeepClone()
+		safeSendErrSignal(g.errSender, deepCloneErr)
+		err := g.storage.SaveDayLog(g.ctx, deepClone, dayLog)
+		safeSendErrSignal(g.errSender, err)
+	}
+	g.LastWords(dayLog.GetKicked())
+	g.handleAFK()
+	g.handleRevives()
 
-			// Validate is final?
-			winnerTeam = g.UnderstandWinnerTeam()
-			fool := (*g.dead.ConvertToPlayers().SearchAllPlayersWithRole(rolesPack.Fool))[0]
-
-			if dayLog.Kicked != nil && *dayLog.Kicked == fool.ID {
-				finishLogValue := g.NewFinishLog(nil, true)
-				finishLog = &finishLogValue
-				isNeedToContinue = false
-				break
-			} else if winnerTeam != nil {
-				finishLogValue := g.NewFinishLog(winnerTeam, false)
-				finishLog = &finishLogValue
-				isNeedToContinue = false
-				break
-			}
-			g.ClearDayVotes()
-		}
+	return g.understandFinishLog()
+}
 
-		if !isNeedToContinue {
-			break
-		}
+// understandFinishLog Validate is final?
+func (g *Game) understandFinishLog() *FinishLog {
+	if soloWinner := g.UnderstandSoloWinner(); soloWinner != nil {
+		finishLog := g.NewSoloFinishLog(soloWinner)
+		return &finishLog
 	}
-	g.ClearDayVotes()
-	return
+	if winnerTeam := g.UnderstandWinnerTeam(); winnerTeam != nil {
+		finishLog := g.NewFinishLog(winnerTeam, false)
+		return &finishLog
+	}
+	return nil
 }
 
 // ********************
@@ -573,8 +757,9 @@ func (g *Game) FinishByFinishLog(l FinishLog) {
 		g.errSender <- newErrSignal(err)
 	}
 	g.finishFuncOnce.Do(func() {
-		g.endTime = time.Now()
+		g.endTime = g.clock.Now()
 		g.SetState(FinishState)
+		g.record(GameFinishedEvent, GameFinishedData{Log: &l, EndTime: g.endTime})
 		if g.storage != nil {
 			deepClone, deepCloneErr := g.GetDeepClone()
 			safeSendErrSignal(g.errSender, deepCloneErr)
@@ -600,13 +785,14 @@ func (g *Game) replaceCtx() {
 // FinishAnyway is used to end the running game anyway.
 func (g *Game) FinishAnyway() {
 	g.finishFuncOnce.Do(func() {
-		g.endTime = time.Now()
+		g.endTime = g.clock.Now()
 		if g.mainChannel != nil {
 			content := "The game was suspended."
 			_, err := g.mainChannel.Write([]byte(g.messenger.Finish.f.Bold(content)))
 			safeSendErrSignal(g.errSender, err)
 		}
 		g.SetState(FinishState)
+		g.record(GameFinishedEvent, GameFinishedData{EndTime: g.endTime})
 		g.replaceCtx()
 		g.finish()
 	})
@@ -680,6 +866,6 @@ func (g *Game) finish() {
 			return
 		}
 
-		g.infoSender <- g.newFinishGameSignal()
+		g.sendInfo(g.newFinishGameSignal())
 	})
 }
@@ -7,12 +7,15 @@ import (
 	configPack "github.com/https-whoyan/MafiaCore/config"
 	playerPack "github.com/https-whoyan/MafiaCore/player"
 	rolesPack "github.com/https-whoyan/MafiaCore/roles"
+	timePack "github.com/https-whoyan/MafiaCore/time"
 )
 
 func (g *Game) GuildID() string {
 	return g.guildID
 }
 func (g *Game) GetState() State {
+	g.RLock()
+	defer g.RUnlock()
 	return g.state
 }
 
@@ -48,6 +51,15 @@ func (g *Game) GetEndTime() time.Time {
 func (g *Game) GetVotePing() int {
 	return g.votePing
 }
+func (g *Game) GetTimings() timePack.Timings {
+	return g.timings
+}
+func (g *Game) GetClock() timePack.Clock {
+	return g.clock
+}
+func (g *Game) GetSeed() int64 {
+	return g.seed
+}
 func (g *Game) GameMessenger() Messenger {
 	return *g.messenger
 }
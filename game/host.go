@@ -0,0 +1,216 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	channelPack "github.com/https-whoyan/MafiaCore/channel"
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+)
+
+// This file contains the controls of the host of the game.
+//
+// The host can pause the game (the timers of the night and day phases are frozen, the votes are still accepted),
+// skip the current voting, extend its deadline, kill the misbehaving player or return the dead one.
+// ForceKill and Revive, as KickPlayer, are applied at the end of the current night or day,
+// so the votes of the current phase stay correct.
+//
+// Every control is recorded in the journal (HostActionData) and sent as HostActionInfo.
+
+var (
+	GameIsNotRunningErr  = errors.New("game is not running")
+	AlreadyPausedErr     = errors.New("game is already paused")
+	NotPausedErr         = errors.New("game is not paused")
+	NoCurrentTimerErr    = errors.New("no current timer")
+	IncorrectDurationErr = errors.New("incorrect duration")
+	PlayerIsNotDeadErr   = errors.New("player is not dead")
+)
+
+type HostAction string
+
+const (
+	PauseHostAction          HostAction = "pause"
+	ResumeHostAction         HostAction = "resume"
+	SkipHostAction           HostAction = "skip"
+	ExtendDeadlineHostAction HostAction = "extend deadline"
+	ForceKillHostAction      HostAction = "force kill"
+	ReviveHostAction         HostAction = "revive"
+)
+
+// Pause freezes the timer of the current phase and all the next ones, until Resume.
+func (g *Game) Pause() error {
+	g.Lock()
+	defer g.Unlock()
+	if !g.IsRunning() {
+		return GameIsNotRunningErr
+	}
+	if g.isPaused {
+		return AlreadyPausedErr
+	}
+	g.isPaused = true
+	if t := g.currentTimer; t != nil && t.Stop() {
+		t.isPaused = true
+		t.remaining = max(t.deadline.Sub(g.clock.Now()), 0)
+	}
+	safeSendErrSignal(g.errSender, g.messenger.Host.SendPausedMessage(g.mainChannel))
+	g.sendHostAction(HostActionInfo{Action: PauseHostAction})
+	return nil
+}
+
+// Resume continues the timer of the current phase from the moment of Pause.
+func (g *Game) Resume() error {
+	g.Lock()
+	defer g.Unlock()
+	if !g.isPaused {
+		return NotPausedErr
+	}
+	g.isPaused = false
+	if t := g.currentTimer; t != nil && t.isPaused {
+		t.isPaused = false
+		t.deadline = g.clock.Now().Add(t.remaining)
+		t.Reset(t.remaining)
+	}
+	safeSendErrSignal(g.errSender, g.messenger.Host.SendResumedMessage(g.mainChannel))
+	g.sendHostAction(HostActionInfo{Action: ResumeHostAction})
+	return nil
+}
+
+// IsPaused reports whether the game is paused.
+func (g *Game) IsPaused() bool {
+	g.RLock()
+	defer g.RUnlock()
+	return g.isPaused
+}
+
+// SkipCurrentVoting ends the timer of the current phase right now, even if the game is paused.
+//
+// The night voting of the role ends as after the deadline (the votes are stood by the game),
+// but the players are not considered AFK. The day voting ends with the votes, that have been set.
+// At the defense and the last words the turn goes to the next speaker.
+func (g *Game) SkipCurrentVoting() error {
+	g.Lock()
+	defer g.Unlock()
+	t := g.currentTimer
+	if t == nil {
+		return NoCurrentTimerErr
+	}
+	t.isSkipped = true
+	t.isPaused = false
+	t.Reset(0)
+	g.sendHostAction(HostActionInfo{Action: SkipHostAction})
+	return nil
+}
+
+// ExtendDeadline adds d to the deadline of the current phase.
+func (g *Game) ExtendDeadline(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%w: %v", IncorrectDurationErr, d)
+	}
+	g.Lock()
+	defer g.Unlock()
+	t := g.currentTimer
+	if t == nil {
+		return NoCurrentTimerErr
+	}
+	switch {
+	case t.isPaused:
+		t.remaining += d
+	case t.Stop():
+		t.deadline = t.deadline.Add(d)
+		t.Reset(max(t.deadline.Sub(g.clock.Now()), 0))
+	default:
+		// The timer has already fired.
+		return NoCurrentTimerErr
+	}
+	safeSendErrSignal(g.errSender, g.messenger.Host.SendDeadlineExtendedMessage(g.mainChannel, d))
+	g.sendHostAction(HostActionInfo{Action: ExtendDeadlineHostAction, Duration: d})
+	return nil
+}
+
+// ForceKill kills the player at the end of the current night or day.
+// Empty reason means player.KilledByHost.
+func (g *Game) ForceKill(id playerPack.IDType, reason playerPack.DeadReason) error {
+	if reason == "" {
+		reason = playerPack.KilledByHost
+	}
+	g.Lock()
+	defer g.Unlock()
+	if g.active.GetByIDType(id) == nil {
+		return fmt.Errorf("%w: %v", PlayerNotFoundErr, id)
+	}
+	g.leaving[id] = reason
+	g.sendHostAction(HostActionInfo{Action: ForceKillHostAction, PlayerID: id, Reason: reason})
+	return nil
+}
+
+// Revive returns the dead player to the game at the end of the current night or day,
+// with the same ID and role.
+func (g *Game) Revive(id playerPack.IDType) error {
+	g.Lock()
+	defer g.Unlock()
+	if g.dead.ConvertToPlayers().GetByIDType(id) == nil {
+		return fmt.Errorf("%w: %v", PlayerIsNotDeadErr, id)
+	}
+	g.reviving[id] = true
+	g.sendHostAction(HostActionInfo{Action: ReviveHostAction, PlayerID: id})
+	return nil
+}
+
+// ____________
+// Internal
+// ____________
+
+// sendHostAction used under the game lock.
+func (g *Game) sendHostAction(info HostActionInfo) {
+	g.record(HostActionEvent, HostActionData{
+		Action:   info.Action,
+		PlayerID: info.PlayerID,
+		Reason:   info.Reason,
+		Duration: info.Duration,
+	})
+	g.sendInfo(g.newInfoSignal(HostActionSignal, info))
+}
+
+// handleRevives returns the players of Revive to the game and to their channels.
+// Called at the end of the night and of the day, after handleAFK.
+func (g *Game) handleRevives() {
+	if g.isInterrupted() {
+		return
+	}
+	g.Lock()
+	ids := make([]playerPack.IDType, 0, len(g.reviving))
+	for id := range g.reviving {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	g.reviving = make(map[playerPack.IDType]bool)
+
+	var revived []*playerPack.Player
+	for _, id := range ids {
+		p := g.dead.ToAlive(id, g.active)
+		if p == nil {
+			continue
+		}
+		revived = append(revived, p)
+		g.record(PlayerRevivedEvent, PlayerRevivedData{PlayerID: id})
+		g.sendInfo(g.newInfoSignal(PlayerRevivedSignal, PlayerRevivedInfo{PlayerID: id}))
+	}
+	mainChannel := g.mainChannel
+	roleChannels := g.roleChannels
+	g.Unlock()
+
+	// The dead players are the spectators of all channels, see moveToSpectators.
+	for _, p := range revived {
+		for role, roleChannel := range roleChannels {
+			if role == p.Role {
+				safeSendErrSignal(g.errSender, channelPack.FromSpectatorToUser(roleChannel, p.Tag))
+			} else {
+				safeSendErrSignal(g.errSender, roleChannel.RemoveUser(p.Tag))
+			}
+		}
+		safeSendErrSignal(g.errSender, channelPack.FromSpectatorToUser(mainChannel, p.Tag))
+		safeSendErrSignal(g.errSender, g.messenger.Host.SendPlayerRevivedMessage(mainChannel, p))
+	}
+}
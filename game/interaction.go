@@ -1,167 +1,49 @@
 package game
 
 import (
-	"strconv"
-
-	"github.com/https-whoyan/MafiaCore/player"
-	"github.com/https-whoyan/MafiaCore/roles"
+	channelPack "github.com/https-whoyan/MafiaCore/channel"
+	fmtPack "github.com/https-whoyan/MafiaCore/fmt"
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/registry"
+	rolesPack "github.com/https-whoyan/MafiaCore/roles"
 )
 
-// All interactions for roles are declared here to avoid cyclic import.
-
-type InteractionMessage string
-
-func (g *Game) nightInteraction(p *player.Player) *InteractionMessage {
-	if p.Role.NightVoteOrder == -1 {
-		return nil
-	}
-	switch p.Role {
-	case roles.Peaceful:
-		return nil
-	case roles.Fool:
-		return nil
-	case roles.Mafia:
-		g.mafiaInteraction(p)
-		return nil
-	case roles.Doctor:
-		g.doctorInteraction(p)
-		return nil
-	case roles.Don:
-		return g.donInteraction(p)
-	case roles.Detective:
-		return g.detectiveInteraction(p)
-	case roles.Whore:
-		g.whoreInteraction(p)
-		return nil
-	case roles.Maniac:
-		g.maniacInteraction(p)
-		return nil
-	case roles.Citizen:
-		g.citizenInteraction(p)
-	}
-	return nil
-}
-
-/* Mafia */
-
-func (g *Game) mafiaInteraction(mafia *player.Player) {
-	g.Lock()
-	defer g.Unlock()
-	nextDeadPlayer, isEmpty := g.interactionHelper(mafia)
-	if isEmpty {
-		return
-	}
-
-	nextDeadPlayer.LifeStatus = player.Dead
-}
-func (g *Game) donInteraction(don *player.Player) *InteractionMessage {
-	g.Lock()
-	defer g.Unlock()
-	f := g.messenger.f
-
-	checkedPlayer, isEmpty := g.interactionHelper(don)
-	if isEmpty {
-		return nil
-	}
-
-	checkedPlayerRoleName := checkedPlayer.Role.Name
-
-	message := InteractionMessage("Checked player " + f.Block(strconv.Itoa(int(checkedPlayer.ID))) + ", role: " +
-		g.messenger.f.Block(checkedPlayerRoleName))
-	return &message
-}
-
-/* Maniac */
+// All interactions of the roles are declared in the registry package,
+// here the game only gives them access to itself.
 
-func (g *Game) maniacInteraction(maniac *player.Player) {
-	// Same as mafia
-	g.Lock()
-	defer g.Unlock()
-	nextDeadPlayer, isEmpty := g.interactionHelper(maniac)
-	if isEmpty {
-		return
-	}
+type InteractionMessage = registry.InteractionMessage
 
-	nextDeadPlayer.LifeStatus = player.Dead
+// gameEnv implementation of registry.Env.
+//
+// Used only under the game lock.
+type gameEnv struct {
+	g *Game
 }
 
-/* Peaceful */
-
-func (g *Game) doctorInteraction(doctor *player.Player) {
-	g.Lock()
-	defer g.Unlock()
-	toVotedPlayer, isEmpty := g.interactionHelper(doctor)
-	if isEmpty {
-		return
-	}
-
-	if toVotedPlayer.LifeStatus == player.Dead {
-		toVotedPlayer.LifeStatus = player.Alive
-	}
+func (e gameEnv) Active() *playerPack.Players   { return e.g.active }
+func (e gameEnv) Dead() *playerPack.DeadPlayers { return e.g.dead }
+func (e gameEnv) FMT() fmtPack.FmtInterface     { return e.g.messenger.f }
+func (e gameEnv) NightCounter() int             { return e.g.nightCounter }
+func (e gameEnv) RoleChannel(role *rolesPack.Role) channelPack.RoleChannel {
+	return e.g.roleChannels[role]
 }
-func (g *Game) detectiveInteraction(detective *player.Player) *InteractionMessage {
-	g.Lock()
-	defer g.Unlock()
 
-	checkedID1 := detective.Votes[len(detective.Votes)-1]
-	checkedID2 := detective.Votes[len(detective.Votes)-2]
+func (g *Game) env() registry.Env { return gameEnv{g: g} }
 
-	if checkedID1 == EmptyVoteInt && checkedID2 == EmptyVoteInt {
+func (g *Game) nightInteraction(p *playerPack.Player) *InteractionMessage {
+	if p.Role.NightVoteOrder == -1 {
 		return nil
 	}
-
-	f := g.messenger.f
-	checkedPlayer1 := g.active.SearchPlayerByGameID(strconv.Itoa(int(checkedID1)))
-	checkedPlayer2 := g.active.SearchPlayerByGameID(strconv.Itoa(int(checkedID2)))
-
-	isEqualsTeams := checkedPlayer1.Role.Team == checkedPlayer2.Role.Team
-
-	var message string
-
-	if isEqualsTeams {
-		message = "Players with id's " + f.Block(strconv.Itoa(int(checkedPlayer1.ID))) + ", " +
-			f.Block(strconv.Itoa(int(checkedPlayer2.ID))) + f.Bold(" in one team.")
-	} else {
-		message = "Players with id's " + f.Block(strconv.Itoa(int(checkedPlayer1.ID))) + ", " +
-			f.Block(strconv.Itoa(int(checkedPlayer2.ID))) + f.Bold(" in different team.")
-	}
-	typedMessage := InteractionMessage(message)
-	return &typedMessage
-}
-func (g *Game) whoreInteraction(whore *player.Player) {
-	g.Lock()
-	defer g.Unlock()
-	mutedPlayer, isEmpty := g.interactionHelper(whore)
-	if isEmpty {
-		return
-	}
-	mutedPlayer.InteractionStatus = player.Muted
-}
-func (g *Game) citizenInteraction(citizen *player.Player) {
 	g.Lock()
 	defer g.Unlock()
-	defendedPlayer, isEmpty := g.interactionHelper(citizen)
-	if isEmpty {
-		return
-	}
-
-	// Citizen is calculated by the most recent, then, if a civilian was killed, her
-	// status would definitely be dead.
-	defendedPlayer.LifeStatus = player.Alive
-	if citizen.LifeStatus == player.Dead {
-		defendedPlayer.LifeStatus = player.Dead
-	}
-}
-
-/* Helper */
-
-func (g *Game) interactionHelper(p *player.Player) (toVoted *player.Player, isEmpty bool) {
-	lastVote := p.Votes[len(p.Votes)-1]
-
-	if lastVote == EmptyVoteInt {
-		isEmpty = true
-		return
-	}
-	toVoted = (*g.active)[lastVote]
-	return
+	before := g.getPlayerStatuses()
+	message := registry.GetBehavior(p.Role).NightAction(g.env(), p)
+	g.recordChangedStatuses(before)
+	for _, id := range g.active.GetIDs() {
+		status, ok := before[id]
+		if ok && status.interactionStatus != playerPack.Muted && (*g.active)[id].InteractionStatus == playerPack.Muted {
+			g.sendInfo(g.newInfoSignal(PlayerMutedSignal, PlayerMutedInfo{PlayerID: id, By: p.Role}))
+		}
+	}
+	return message
 }
@@ -0,0 +1,516 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	configPack "github.com/https-whoyan/MafiaCore/config"
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+)
+
+// This file contains the journal of the game.
+//
+// Every state-changing action of the game is recorded as a typed Event.
+// Using Replay, the events can be turned back into DeepCloneGame at any point of the game.
+
+// ____________________
+// Event
+// ____________________
+
+type Event struct {
+	// Seq number of the event in the journal, starts with 0.
+	Seq         int       `json:"seq"`
+	InitialTime time.Time `json:"initialTime"`
+	EventType   EventType `json:"eventType"`
+	Data        eventData `json:"data"`
+}
+
+type EventType uint8
+
+const (
+	GameStartedEvent EventType = iota
+	StateSwitchedEvent
+	VotingRoleSwitchedEvent
+	NightVoteAcceptedEvent
+	DayVoteAcceptedEvent
+	VoteRejectedEvent
+	TimerExpiredEvent
+	PlayerStatusChangedEvent
+	InteractionsResetEvent
+	DayVotesClearedEvent
+	RoleSwitchedEvent
+	PlayerDiedEvent
+	SpectatorsMovedEvent
+	NightFinishedEvent
+	DayFinishedEvent
+	GameFinishedEvent
+	GameRestoredEvent
+	DayPhaseSwitchedEvent
+	PlayerReplacedEvent
+	HostActionEvent
+	PlayerRevivedEvent
+	DayVoteWithdrawnEvent
+)
+
+// See GameStartedData, StateSwitchedData, VotingRoleSwitchedData, NightVoteData, DayVoteData,
+// VoteRejectedData, TimerExpiredData, PlayerStatusChangedData, InteractionsResetData,
+// DayVotesClearedData, RoleSwitchedData, PlayerDiedData, SpectatorsMovedData,
+// NightFinishedData, DayFinishedData, GameFinishedData, GameRestoredData, DayPhaseSwitchedData,
+// PlayerReplacedData, HostActionData, PlayerRevivedData, DayVoteWithdrawnData
+type eventData interface {
+	eventDataPrivateMethod()
+}
+
+// DealtPlayer is a player at the moment of the game start.
+type DealtPlayer struct {
+	playerPack.NonPlayingPlayer
+	ID       playerPack.IDType `json:"id"`
+	RoleName string            `json:"role"`
+}
+
+// GameStartedData is the first event of the journal (or GameRestoredData, if the game was restored).
+type GameStartedData struct {
+	GuildID         string                        `json:"guildID"`
+	RolesConfig     *configPack.RolesConfig       `json:"rolesConfig"`
+	StartPlayers    []playerPack.NonPlayingPlayer `json:"startPlayers"`
+	Spectators      []playerPack.NonPlayingPlayer `json:"spectators"`
+	Players         []DealtPlayer                 `json:"players"`
+	TimeStart       time.Time                     `json:"timeStart"`
+	VoteForYourself bool                          `json:"voteForYourself"`
+	VotePing        int                           `json:"votePing"`
+	RenameMode      RenameMode                    `json:"renameMode"`
+	Seed            int64                         `json:"seed"`
+	PreviousState   State                         `json:"previousState"`
+	State           State                         `json:"state"`
+}
+
+func (GameStartedData) eventDataPrivateMethod() {}
+
+type StateSwitchedData struct {
+	NightCounter  int   `json:"nightCounter"`
+	PreviousState State `json:"previousState"`
+	NewState      State `json:"newState"`
+}
+
+func (StateSwitchedData) eventDataPrivateMethod() {}
+
+// VotingRoleSwitchedData RoleName is empty, if the night voting is over.
+type VotingRoleSwitchedData struct {
+	RoleName string `json:"role"`
+}
+
+func (VotingRoleSwitchedData) eventDataPrivateMethod() {}
+
+// NightVoteData IsAuto is true, if the vote was stood by the game (deadline or the same votes of the role).
+// IsSuperseded is true, if the votes replace the previous votes of the voter in the same turn.
+// IsLeader is true, if the voter votes in the turn of another role as its Leader (see TeamVotePolicy).
+type NightVoteData struct {
+	VoterID      playerPack.IDType   `json:"voter"`
+	Votes        []playerPack.IDType `json:"votes"`
+	IsAuto       bool                `json:"isAuto"`
+	IsSuperseded bool                `json:"isSuperseded"`
+	IsLeader     bool                `json:"isLeader"`
+}
+
+func (NightVoteData) eventDataPrivateMethod() {}
+
+type DayVoteData struct {
+	VoterID playerPack.IDType `json:"voter"`
+	Vote    playerPack.IDType `json:"vote"`
+}
+
+func (DayVoteData) eventDataPrivateMethod() {}
+
+// DayVoteWithdrawnData the player has withdrawn his vote (see Game.WithdrawDayVote).
+type DayVoteWithdrawnData struct {
+	VoterID playerPack.IDType `json:"voter"`
+}
+
+func (DayVoteWithdrawnData) eventDataPrivateMethod() {}
+
+// VoteRejectedData contains the vote as it was passed to the game.
+type VoteRejectedData struct {
+	State      State    `json:"state"`
+	VoterID    string   `json:"voter"`
+	IsServerID bool     `json:"isServerID"`
+	Votes      []string `json:"votes"`
+	Err        error    `json:"-"`
+	Reason     string   `json:"reason"`
+}
+
+func (VoteRejectedData) eventDataPrivateMethod() {}
+
+// TimerExpiredData RoleName is empty at day.
+type TimerExpiredData struct {
+	State    State  `json:"state"`
+	RoleName string `json:"role"`
+}
+
+func (TimerExpiredData) eventDataPrivateMethod() {}
+
+// PlayerStatusChangedData is recorded, when a night interaction changes a player.
+type PlayerStatusChangedData struct {
+	PlayerID          playerPack.IDType      `json:"player"`
+	LifeStatus        playerPack.AliveStatus `json:"lifeStatus"`
+	InteractionStatus playerPack.VoteStatus  `json:"interactionStatus"`
+}
+
+func (PlayerStatusChangedData) eventDataPrivateMethod() {}
+
+type InteractionsResetData struct{}
+
+func (InteractionsResetData) eventDataPrivateMethod() {}
+
+type DayVotesClearedData struct{}
+
+func (DayVotesClearedData) eventDataPrivateMethod() {}
+
+type RoleSwitchedData struct {
+	PlayerID     playerPack.IDType `json:"player"`
+	FromRoleName string            `json:"from"`
+	ToRoleName   string            `json:"to"`
+}
+
+func (RoleSwitchedData) eventDataPrivateMethod() {}
+
+type PlayerDiedData struct {
+	PlayerID  playerPack.IDType     `json:"player"`
+	Reason    playerPack.DeadReason `json:"reason"`
+	LivedDays int                   `json:"livedDays"`
+}
+
+func (PlayerDiedData) eventDataPrivateMethod() {}
+
+type SpectatorsMovedData struct {
+	Tags []string `json:"tags"`
+}
+
+func (SpectatorsMovedData) eventDataPrivateMethod() {}
+
+type NightFinishedData struct {
+	Log NightLog `json:"log"`
+}
+
+func (NightFinishedData) eventDataPrivateMethod() {}
+
+type DayFinishedData struct {
+	Log DayLog `json:"log"`
+}
+
+func (DayFinishedData) eventDataPrivateMethod() {}
+
+type DayPhaseSwitchedData struct {
+	Phase      DayPhase            `json:"phase"`
+	Candidates []playerPack.IDType `json:"candidates,omitempty"`
+	Speaker    *playerPack.IDType  `json:"speaker,omitempty"`
+}
+
+func (DayPhaseSwitchedData) eventDataPrivateMethod() {}
+
+// PlayerReplacedData the User took the seat of the player with OldTag. See Game.ReplacePlayer.
+type PlayerReplacedData struct {
+	PlayerID playerPack.IDType           `json:"player"`
+	OldTag   string                      `json:"oldTag"`
+	User     playerPack.NonPlayingPlayer `json:"user"`
+}
+
+func (PlayerReplacedData) eventDataPrivateMethod() {}
+
+// HostActionData is recorded on every control of the host, same as HostActionInfo.
+// The kill and the revival themselves are recorded later, as PlayerDiedData and PlayerRevivedData.
+type HostActionData struct {
+	Action   HostAction            `json:"action"`
+	PlayerID playerPack.IDType     `json:"player,omitempty"`
+	Reason   playerPack.DeadReason `json:"reason,omitempty"`
+	Duration time.Duration         `json:"duration,omitempty"`
+}
+
+func (HostActionData) eventDataPrivateMethod() {}
+
+// PlayerRevivedData the dead player is returned to the game. See Game.Revive.
+type PlayerRevivedData struct {
+	PlayerID playerPack.IDType `json:"player"`
+}
+
+func (PlayerRevivedData) eventDataPrivateMethod() {}
+
+// GameFinishedData Log is nil, if the game was finished by FinishAnyway.
+type GameFinishedData struct {
+	Log     *FinishLog `json:"log"`
+	EndTime time.Time  `json:"endTime"`
+}
+
+func (GameFinishedData) eventDataPrivateMethod() {}
+
+// GameRestoredData is the first event of the journal of the restored game. See Restore.
+//
+// Game does not contain channels.
+type GameRestoredData struct {
+	Game DeepCloneGame `json:"game"`
+}
+
+func (GameRestoredData) eventDataPrivateMethod() {}
+
+// ____________________
+// JSON
+// ____________________
+
+var UnknownEventTypeErr = errors.New("unknown event type")
+
+// UnmarshalJSON decodes Data by EventType, so the saved events can be passed to Replay.
+//
+// VoteRejectedData.Err is not saved, use Reason.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var j struct {
+		Seq         int             `json:"seq"`
+		InitialTime time.Time       `json:"initialTime"`
+		EventType   EventType       `json:"eventType"`
+		Data        json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	decode, ok := eventDataDecoders[j.EventType]
+	if !ok {
+		return fmt.Errorf("%w: %v", UnknownEventTypeErr, j.EventType)
+	}
+	data, err := decode(j.Data)
+	if err != nil {
+		return err
+	}
+	*e = Event{Seq: j.Seq, InitialTime: j.InitialTime, EventType: j.EventType, Data: data}
+	return nil
+}
+
+var eventDataDecoders = map[EventType]func(json.RawMessage) (eventData, error){
+	GameStartedEvent:         decodeEventData[GameStartedData],
+	StateSwitchedEvent:       decodeEventData[StateSwitchedData],
+	VotingRoleSwitchedEvent:  decodeEventData[VotingRoleSwitchedData],
+	NightVoteAcceptedEvent:   decodeEventData[NightVoteData],
+	DayVoteAcceptedEvent:     decodeEventData[DayVoteData],
+	VoteRejectedEvent:        decodeEventData[VoteRejectedData],
+	TimerExpiredEvent:        decodeEventData[TimerExpiredData],
+	PlayerStatusChangedEvent: decodeEventData[PlayerStatusChangedData],
+	InteractionsResetEvent:   decodeEventData[InteractionsResetData],
+	DayVotesClearedEvent:     decodeEventData[DayVotesClearedData],
+	RoleSwitchedEvent:        decodeEventData[RoleSwitchedData],
+	PlayerDiedEvent:          decodeEventData[PlayerDiedData],
+	SpectatorsMovedEvent:     decodeEventData[SpectatorsMovedData],
+	NightFinishedEvent:       decodeEventData[NightFinishedData],
+	DayFinishedEvent:         decodeEventData[DayFinishedData],
+	GameFinishedEvent:        decodeEventData[GameFinishedData],
+	GameRestoredEvent:        decodeEventData[GameRestoredData],
+	DayPhaseSwitchedEvent:    decodeEventData[DayPhaseSwitchedData],
+	PlayerReplacedEvent:      decodeEventData[PlayerReplacedData],
+	HostActionEvent:          decodeEventData[HostActionData],
+	PlayerRevivedEvent:       decodeEventData[PlayerRevivedData],
+	DayVoteWithdrawnEvent:    decodeEventData[DayVoteWithdrawnData],
+}
+
+func decodeEventData[T eventData](raw json.RawMessage) (eventData, error) {
+	var data T
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ____________________
+// EventStorage
+// ____________________
+
+// EventStorage is an optional extension of Storage.
+//
+// If the Storage of the game implements it, every event is saved right after it was recorded.
+type EventStorage interface {
+	SaveEvent(ctx context.Context, guildID string, e Event) error
+}
+
+// ____________________
+// Journal
+// ____________________
+
+type journal struct {
+	sync.Mutex
+	events []Event
+	// The events, which are not saved to EventStorage yet.
+	unsaved  []Event
+	isSaving bool
+}
+
+func newJournal() *journal {
+	return &journal{events: make([]Event, 0)}
+}
+
+// record adds new event to the journal.
+//
+// The journal starts with GameStartedEvent (or GameRestoredEvent), all events before it are not recorded.
+// Can be used under the game lock, the event is saved to EventStorage later (see saveEvents).
+func (g *Game) record(eventType EventType, data eventData) {
+	g.journal.Lock()
+	defer g.journal.Unlock()
+	isStartEvent := eventType == GameStartedEvent || eventType == GameRestoredEvent
+	if len(g.journal.events) == 0 && !isStartEvent {
+		return
+	}
+	e := Event{
+		Seq:         len(g.journal.events),
+		InitialTime: g.clock.Now(),
+		EventType:   eventType,
+		Data:        data,
+	}
+	g.journal.events = append(g.journal.events, e)
+
+	// The events are saved in order, but not under the game lock.
+	if _, ok := g.storage.(EventStorage); ok {
+		g.journal.unsaved = append(g.journal.unsaved, e)
+		if !g.journal.isSaving {
+			g.journal.isSaving = true
+			go g.saveEvents()
+		}
+	}
+}
+
+func (g *Game) saveEvents() {
+	eventStorage := g.storage.(EventStorage)
+	for {
+		g.journal.Lock()
+		if len(g.journal.unsaved) == 0 {
+			g.journal.isSaving = false
+			g.journal.Unlock()
+			return
+		}
+		e := g.journal.unsaved[0]
+		g.journal.unsaved = g.journal.unsaved[1:]
+		g.journal.Unlock()
+
+		safeSendErrSignal(g.errSender, eventStorage.SaveEvent(g.ctx, g.guildID, e))
+	}
+}
+
+// GetEvents returns a copy of all recorded events of the game.
+func (g *Game) GetEvents() []Event {
+	g.journal.Lock()
+	defer g.journal.Unlock()
+	events := make([]Event, len(g.journal.events))
+	copy(events, g.journal.events)
+	return events
+}
+
+// Used under the game lock.
+func (g *Game) newGameStartedData() GameStartedData {
+	data := GameStartedData{
+		GuildID:         g.guildID,
+		RolesConfig:     g.rolesConfig,
+		TimeStart:       g.timeStart,
+		VoteForYourself: g.voteForYourself,
+		VotePing:        g.votePing,
+		RenameMode:      g.renameMode,
+		Seed:            g.seed,
+		PreviousState:   g.previousState,
+		State:           g.state,
+	}
+	for _, p := range *g.startPlayers {
+		data.StartPlayers = append(data.StartPlayers, *p)
+	}
+	for _, p := range *g.spectators {
+		data.Spectators = append(data.Spectators, *p)
+	}
+	for _, id := range g.active.GetIDs() {
+		p := (*g.active)[id]
+		data.Players = append(data.Players, DealtPlayer{
+			NonPlayingPlayer: p.NonPlayingPlayer,
+			ID:               p.ID,
+			RoleName:         p.Role.Name,
+		})
+	}
+	return data
+}
+
+// Used under the game lock.
+func (g *Game) recordVotingRoleSwitched() {
+	var roleName string
+	if g.nightVoting != nil {
+		roleName = g.nightVoting.Name
+	}
+	g.record(VotingRoleSwitchedEvent, VotingRoleSwitchedData{RoleName: roleName})
+}
+
+func (g *Game) recordVoteRejected(err error, voterID string, isServerID bool, votes ...string) {
+	g.RLock()
+	state := g.state
+	g.RUnlock()
+	g.record(VoteRejectedEvent, VoteRejectedData{
+		State:      state,
+		VoterID:    voterID,
+		IsServerID: isServerID,
+		Votes:      votes,
+		Err:        err,
+		Reason:     err.Error(),
+	})
+}
+
+// timerExpired records and informs, that the voting is ended by the deadline.
+func (g *Game) timerExpired() {
+	g.RLock()
+	data := TimerExpiredData{State: g.state}
+	if g.nightVoting != nil {
+		data.RoleName = g.nightVoting.Name
+	}
+	info := TimerExpiredInfo{
+		State:      g.state,
+		VotingRole: g.nightVoting,
+		DayPhase:   g.dayPhase,
+	}
+	g.RUnlock()
+	g.record(TimerExpiredEvent, data)
+	g.sendInfo(g.newInfoSignal(TimerExpiredSignal, info))
+}
+
+type playerStatus struct {
+	lifeStatus        playerPack.AliveStatus
+	interactionStatus playerPack.VoteStatus
+}
+
+// Used under the game lock.
+func (g *Game) getPlayerStatuses() map[playerPack.IDType]playerStatus {
+	statuses := make(map[playerPack.IDType]playerStatus)
+	for _, p := range *g.active {
+		statuses[p.ID] = playerStatus{
+			lifeStatus:        p.LifeStatus,
+			interactionStatus: p.InteractionStatus,
+		}
+	}
+	return statuses
+}
+
+// recordChangedStatuses records all players, whose statuses are differ from before.
+//
+// Used under the game lock.
+func (g *Game) recordChangedStatuses(before map[playerPack.IDType]playerStatus) {
+	for _, id := range g.active.GetIDs() {
+		p := (*g.active)[id]
+		prev, ok := before[p.ID]
+		if ok && prev.lifeStatus == p.LifeStatus && prev.interactionStatus == p.InteractionStatus {
+			continue
+		}
+		g.record(PlayerStatusChangedEvent, PlayerStatusChangedData{
+			PlayerID:          p.ID,
+			LifeStatus:        p.LifeStatus,
+			InteractionStatus: p.InteractionStatus,
+		})
+	}
+}
+
+func (g *Game) recordRejectedOneVote(err error, vP oneVoteProviderInterface) {
+	if vP == nil {
+		return
+	}
+	voterID, isServerID := vP.GetVotedPlayerID()
+	vote, _ := vP.GetVote()
+	g.recordVoteRejected(err, voterID, isServerID, vote)
+}
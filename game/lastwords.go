@@ -0,0 +1,59 @@
+package game
+
+import (
+	channelPack "github.com/https-whoyan/MafiaCore/channel"
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+)
+
+// LastWords gives the dying players the time (LastWordDeadline of the timings) to say their last words,
+// one by one, and then moves them to the spectators.
+//
+// While the player speaks, only he may write in the main channel,
+// if it implements channelPack.SpeakersChannel.
+func (g *Game) LastWords(dyingIDs []playerPack.IDType) {
+	if len(dyingIDs) == 0 {
+		return
+	}
+	select {
+	case <-g.ctx.Done():
+		return
+	default:
+	}
+
+	g.SetState(LastWordsState)
+	g.sendInfo(g.newSwitchStateSignal())
+
+	g.RLock()
+	deadline := g.timings.LastWordDeadline
+	mainChannel := g.mainChannel
+	dead := g.dead.ConvertToPlayers()
+	g.RUnlock()
+
+	dying := make(playerPack.Players)
+	for _, id := range dyingIDs {
+		if p := dead.GetByIDType(id); p != nil {
+			dying[id] = p
+		}
+	}
+
+	for _, id := range dying.GetIDs() {
+		speaker := dying[id]
+		safeSendErrSignal(g.errSender, channelPack.AllowOnlySpeakers(mainChannel, speaker.Tag))
+		g.sendInfo(g.newLastWordsSignal(speaker.ID, deadline))
+		safeSendErrSignal(g.errSender, g.messenger.LastWords.SendLastWordsMessage(mainChannel, speaker, deadline))
+
+		timer := g.newPhaseTimer(deadline)
+		select {
+		case <-timer.C():
+		case <-g.ctx.Done():
+		}
+		g.stopPhaseTimer(timer)
+		if g.isInterrupted() {
+			break
+		}
+	}
+	safeSendErrSignal(g.errSender, channelPack.AllowAllSpeakers(mainChannel))
+	safeSendErrSignal(g.errSender, g.messenger.LastWords.SendLastWordsAreOverMessage(mainChannel))
+
+	g.moveToSpectators(&dying)
+}
@@ -0,0 +1,380 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	configPack "github.com/https-whoyan/MafiaCore/config"
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+)
+
+// This file contains the registration of the game (RegisterState).
+//
+// Lobby collects the players and the spectators, checks, that everyone is ready,
+// and lets the players vote for the config.
+// When all players are ready, the countdown (LobbyCountdown of the timings) starts,
+// after which the lobby initializes the game (see Game.Init) and sends the result to Done.
+// If the game can not be started, the lobby stays open, and the countdown starts again
+// after the next change of the lobby.
+//
+// The game must have all options and channels set before NewLobby.
+
+var (
+	LobbyIsClosedErr            = errors.New("lobby is closed")
+	LobbyIsFullErr              = errors.New("lobby is full")
+	AlreadyInLobbyErr           = errors.New("user is already in lobby")
+	NotInLobbyErr               = errors.New("user is not in lobby")
+	IncorrectConfigIndexErr     = errors.New("incorrect config index")
+	IncorrectLobbyLimitsErr     = errors.New("incorrect lobby limits")
+	NotEnoughPlayersToConfigErr = errors.New("not enough players to choose config")
+)
+
+type LobbyOption func(l *Lobby)
+
+// LobbyLimitsOpt sets the minimum and maximum number of the players.
+// Limits out of config.GetMinPlayersCount and config.GetMaxPlayersCount are ignored.
+func LobbyLimitsOpt(minPlayers, maxPlayers int) LobbyOption {
+	return func(l *Lobby) {
+		l.minPlayers = max(minPlayers, configPack.GetMinPlayersCount())
+		l.maxPlayers = min(maxPlayers, configPack.GetMaxPlayersCount())
+	}
+}
+
+type Lobby struct {
+	sync.Mutex
+	g *Game
+
+	minPlayers int
+	maxPlayers int
+
+	players    playerPack.NonPlayingPlayers
+	spectators playerPack.NonPlayingPlayers
+	// Key - tag of the player.
+	ready map[string]bool
+	// Key - tag of the player, value - index of the config in GetConfigs.
+	configVotes map[string]int
+
+	// Closed, when the countdown is canceled.
+	cancelCountdown chan struct{}
+	isClosed        bool
+	done            chan error
+}
+
+// NewLobby switches the game to RegisterState and opens the lobby.
+func NewLobby(g *Game, opts ...LobbyOption) (*Lobby, error) {
+	l := &Lobby{
+		g:           g,
+		minPlayers:  configPack.GetMinPlayersCount(),
+		maxPlayers:  configPack.GetMaxPlayersCount(),
+		ready:       make(map[string]bool),
+		configVotes: make(map[string]int),
+		done:        make(chan error, 1),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.minPlayers > l.maxPlayers {
+		return nil, fmt.Errorf("%w: min %v, max %v", IncorrectLobbyLimitsErr, l.minPlayers, l.maxPlayers)
+	}
+	g.SetState(RegisterState)
+	return l, nil
+}
+
+// Done returns the chan, which gets the result of Game.Init after the countdown.
+// nil means, that the game is initialized and can be run.
+// Only the last result is kept, the unread one is replaced.
+func (l *Lobby) Done() <-chan error { return l.done }
+
+// sendResult replaces the unread result of Done with err, so it never blocks.
+//
+// Used under the lobby lock.
+func (l *Lobby) sendResult(err error) {
+	select {
+	case <-l.done:
+	default:
+	}
+	l.done <- err
+}
+
+// ____________________
+// Joins and leaves
+// ____________________
+
+// Join adds the player to the lobby.
+// All config votes are reset, because the list of the configs depends on the number of players.
+func (l *Lobby) Join(p *playerPack.NonPlayingPlayer) error {
+	l.Lock()
+	defer l.Unlock()
+	if err := l.validateJoin(p); err != nil {
+		return err
+	}
+	if len(l.players) >= l.maxPlayers {
+		return LobbyIsFullErr
+	}
+	l.players.Append(p)
+	l.configVotes = make(map[string]int)
+	l.updateCountdown()
+	return nil
+}
+
+// JoinBot fills the seat with the bot (see BotPlayer). The bot is always ready.
+func (l *Lobby) JoinBot(p *playerPack.NonPlayingPlayer, bot BotPlayer) error {
+	if bot == nil {
+		return NilBotErr
+	}
+	l.Lock()
+	defer l.Unlock()
+	if err := l.validateJoin(p); err != nil {
+		return err
+	}
+	if len(l.players) >= l.maxPlayers {
+		return LobbyIsFullErr
+	}
+	if err := l.g.SetBot(p.Tag, bot); err != nil {
+		return err
+	}
+	p.IsBot = true
+	l.players.Append(p)
+	l.ready[p.Tag] = true
+	l.configVotes = make(map[string]int)
+	l.updateCountdown()
+	return nil
+}
+
+// JoinAsSpectator adds the spectator to the lobby.
+func (l *Lobby) JoinAsSpectator(p *playerPack.NonPlayingPlayer) error {
+	l.Lock()
+	defer l.Unlock()
+	if err := l.validateJoin(p); err != nil {
+		return err
+	}
+	l.spectators.Append(p)
+	return nil
+}
+
+// Leave removes the player or the spectator from the lobby.
+func (l *Lobby) Leave(tag string) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.isClosed {
+		return LobbyIsClosedErr
+	}
+	if i := l.indexOfPlayer(tag); i != -1 {
+		l.players = append(l.players[:i], l.players[i+1:]...)
+		delete(l.ready, tag)
+		l.g.removeBot(tag)
+		l.configVotes = make(map[string]int)
+		l.updateCountdown()
+		return nil
+	}
+	for i, spectator := range l.spectators {
+		if spectator.Tag == tag {
+			l.spectators = append(l.spectators[:i], l.spectators[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %v", NotInLobbyErr, tag)
+}
+
+func (l *Lobby) validateJoin(p *playerPack.NonPlayingPlayer) error {
+	if l.isClosed {
+		return LobbyIsClosedErr
+	}
+	if p == nil {
+		return fmt.Errorf("%w: nil player", NotInLobbyErr)
+	}
+	if l.indexOfPlayer(p.Tag) != -1 {
+		return fmt.Errorf("%w: %v", AlreadyInLobbyErr, p.Tag)
+	}
+	for _, spectator := range l.spectators {
+		if spectator.Tag == p.Tag {
+			return fmt.Errorf("%w: %v", AlreadyInLobbyErr, p.Tag)
+		}
+	}
+	return nil
+}
+
+func (l *Lobby) indexOfPlayer(tag string) int {
+	for i, p := range l.players {
+		if p.Tag == tag {
+			return i
+		}
+	}
+	return -1
+}
+
+// ____________________
+// Ready-checks
+// ____________________
+
+// SetReady marks the player as ready (or not).
+// If all players are ready, the countdown starts, if anyone is not ready, it is canceled.
+func (l *Lobby) SetReady(tag string, isReady bool) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.isClosed {
+		return LobbyIsClosedErr
+	}
+	if l.indexOfPlayer(tag) == -1 {
+		return fmt.Errorf("%w: %v", NotInLobbyErr, tag)
+	}
+	if isReady {
+		l.ready[tag] = true
+	} else {
+		delete(l.ready, tag)
+	}
+	l.updateCountdown()
+	return nil
+}
+
+// IsCountdown reports whether the countdown is going.
+func (l *Lobby) IsCountdown() bool {
+	l.Lock()
+	defer l.Unlock()
+	return l.cancelCountdown != nil
+}
+
+func (l *Lobby) canStart() bool {
+	return len(l.players) >= l.minPlayers && len(l.ready) == len(l.players)
+}
+
+// updateCountdown starts or cancels the countdown.
+//
+// Used under the lobby lock.
+func (l *Lobby) updateCountdown() {
+	switch canStart := l.canStart(); {
+	case canStart && l.cancelCountdown == nil:
+		l.cancelCountdown = make(chan struct{})
+		go l.countdown(l.cancelCountdown)
+	case !canStart && l.cancelCountdown != nil:
+		close(l.cancelCountdown)
+		l.cancelCountdown = nil
+	}
+}
+
+func (l *Lobby) countdown(cancel <-chan struct{}) {
+	l.g.RLock()
+	timer := l.g.timer(l.g.timings.LobbyCountdown)
+	ctx := l.g.ctx
+	l.g.RUnlock()
+	defer timer.Stop()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	select {
+	case <-timer.C():
+		l.start(cancel)
+	case <-cancel:
+	case <-ctx.Done():
+	}
+}
+
+// ____________________
+// Configs
+// ____________________
+
+// GetConfigs returns the configs, available for the current number of players.
+func (l *Lobby) GetConfigs() ([]*configPack.RolesConfig, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.getConfigs()
+}
+
+func (l *Lobby) getConfigs() ([]*configPack.RolesConfig, error) {
+	configs, _, err := configPack.GetConfigsByPlayersCount(len(l.players))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", NotEnoughPlayersToConfigErr, err)
+	}
+	return configs, nil
+}
+
+// VoteForConfig sets the vote of the player for the config with the index in GetConfigs.
+func (l *Lobby) VoteForConfig(tag string, index int) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.isClosed {
+		return LobbyIsClosedErr
+	}
+	if l.indexOfPlayer(tag) == -1 {
+		return fmt.Errorf("%w: %v", NotInLobbyErr, tag)
+	}
+	configs, err := l.getConfigs()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(configs) {
+		return fmt.Errorf("%w: %v", IncorrectConfigIndexErr, index)
+	}
+	l.configVotes[tag] = index
+	return nil
+}
+
+// chooseConfig returns the config with the most votes.
+// Tie means the config with the lowest index, no votes means the random config.
+func (l *Lobby) chooseConfig() (*configPack.RolesConfig, error) {
+	configs, err := l.getConfigs()
+	if err != nil {
+		return nil, err
+	}
+	if len(l.configVotes) == 0 {
+		return configs[l.g.rand.Intn(len(configs))], nil
+	}
+	occurrences := make([]int, len(configs))
+	for _, index := range l.configVotes {
+		occurrences[index]++
+	}
+	chosen := 0
+	for index, occurrence := range occurrences {
+		if occurrence > occurrences[chosen] {
+			chosen = index
+		}
+	}
+	return configs[chosen], nil
+}
+
+// ____________________
+// Start
+// ____________________
+
+// GetPlayers returns the players of the lobby in order of the joins.
+func (l *Lobby) GetPlayers() playerPack.NonPlayingPlayers {
+	l.Lock()
+	defer l.Unlock()
+	return append(playerPack.NonPlayingPlayers{}, l.players...)
+}
+
+// GetSpectators returns the spectators of the lobby.
+func (l *Lobby) GetSpectators() playerPack.NonPlayingPlayers {
+	l.Lock()
+	defer l.Unlock()
+	return append(playerPack.NonPlayingPlayers{}, l.spectators...)
+}
+
+// start closes the lobby and initializes the game, if the countdown was not canceled.
+// If the config can not be chosen, or the game can not be started with it, the lobby stays open.
+func (l *Lobby) start(cancel <-chan struct{}) {
+	l.Lock()
+	defer l.Unlock()
+	if l.isClosed || l.cancelCountdown == nil || l.cancelCountdown != cancel {
+		return
+	}
+	l.cancelCountdown = nil
+
+	cfg, err := l.chooseConfig()
+	if err == nil {
+		players := append(playerPack.NonPlayingPlayers{}, l.players...)
+		spectators := append(playerPack.NonPlayingPlayers{}, l.spectators...)
+		l.g.SetStartPlayers(&players)
+		l.g.SetSpectators(&spectators)
+		err = l.g.validationStart(cfg)
+	}
+	if err != nil {
+		l.sendResult(err)
+		return
+	}
+	l.isClosed = true
+	l.sendResult(l.g.Init(cfg))
+}
@@ -30,7 +30,10 @@ type NightLog struct {
 	// Key - ID of the voted player
 	// Value - usually a vote, but in case the role uses 2 votes - 2 votes at once.
 	NightVotes map[player.IDType][]player.IDType `json:"votes"`
-	Dead       []player.IDType                   `json:"dead"`
+	// Key - name of the role, value - the votes, decided by the role (see TeamVotePolicy).
+	// The roles, that have decided nothing, are omitted.
+	Decisions map[string][]player.IDType `json:"decisions"`
+	Dead      []player.IDType            `json:"dead"`
 }
 
 // NewNightLog Gives the log after nightfall.
@@ -62,6 +65,9 @@ func (g *Game) NewNightLog() NightLog {
 
 			var votes []player.IDType
 			n := len(p.Votes)
+			if n == 0 {
+				continue
+			}
 			if p.Role.IsTwoVotes {
 				votes = []player.IDType{p.Votes[n-2], p.Votes[n-1]}
 			} else {
@@ -69,6 +75,12 @@ func (g *Game) NewNightLog() NightLog {
 			}
 			nightVotes[p.ID] = votes
 		}
+		decisions := make(map[string][]player.IDType)
+		for role, turn := range g.nightTurns {
+			if turn.decision != nil {
+				decisions[role.Name] = turn.decision
+			}
+		}
 		var dead []player.IDType
 		for _, p := range *g.active {
 			if p.LifeStatus == player.Dead {
@@ -78,6 +90,7 @@ func (g *Game) NewNightLog() NightLog {
 		return NightLog{
 			NightNumber: nightNumber,
 			NightVotes:  nightVotes,
+			Decisions:   decisions,
 			Dead:        dead,
 		}
 	}
@@ -93,40 +106,54 @@ type DayLog struct {
 	// Value - number of votes.
 	DayVotes map[player.IDType]player.IDType `json:"votes"`
 	Kicked   *player.IDType                  `json:"kicked"`
-	IsSkip   bool                            `json:"isSkip"`
+	// KickedAll presents all kicked players, if there are more than one (see KillAllDayTieBreak).
+	KickedAll []player.IDType `json:"kickedAll,omitempty"`
+	IsSkip    bool            `json:"isSkip"`
+	// Rules of the voting.
+	Rules DayVotingRules `json:"rules"`
+	// Tied presents the players, who got the most votes equally.
+	Tied []player.IDType `json:"tied,omitempty"`
+	// Nominees used only by NominationDayProcedure: all nominated players.
+	Nominees []player.IDType `json:"nominees,omitempty"`
+	// Runoff and RunoffVotes presents the players and the votes of the runoff vote (if it was).
+	Runoff      []player.IDType                 `json:"runoff,omitempty"`
+	RunoffVotes map[player.IDType]player.IDType `json:"runoffVotes,omitempty"`
+}
+
+// GetKicked returns the IDs of all kicked players.
+func (l DayLog) GetKicked() []player.IDType {
+	if len(l.KickedAll) > 0 {
+		return l.KickedAll
+	}
+	if l.Kicked != nil {
+		return []player.IDType{*l.Kicked}
+	}
+	return nil
+}
+
+func (l *DayLog) kick(ids ...player.IDType) {
+	kicked := ids[0]
+	l.Kicked = &kicked
+	l.IsSkip = false
+	if len(ids) > 1 {
+		l.KickedAll = ids
+	}
 }
 
 type FinishLog struct {
-	WinnerTeam  *roles.Team `json:"winnerTeam"`
-	IsFool      bool        `json:"isFool"`
-	TotalNights int         `json:"totalNights"`
+	WinnerTeam *roles.Team `json:"winnerTeam"`
+	IsFool     bool        `json:"isFool"`
+	// SoloWinner presents the name of the role that won the game on its own.
+	// Empty, if the game was won by the team.
+	SoloWinner  string `json:"soloWinner"`
+	TotalNights int    `json:"totalNights"`
+	// Bots presents the IDs of the players, whose seats were filled by the bots.
+	Bots []player.IDType `json:"bots,omitempty"`
 }
 
 func (g *Game) NewFinishLog(winnerTeam *roles.Team, isFool bool) FinishLog {
 	if isFool {
-		// Validation
-
-		g.RLock()
-		defer g.RUnlock()
-
-		containsFool := false
-		for _, players := range *g.dead {
-			for _, p := range players {
-				if p.Role == roles.Fool {
-					containsFool = true
-				}
-			}
-		}
-
-		if !containsFool {
-			panic("Fool is not killed!")
-		}
-
-		return FinishLog{
-			WinnerTeam:  nil,
-			IsFool:      isFool,
-			TotalNights: g.nightCounter,
-		}
+		return g.NewSoloFinishLog(roles.Fool)
 	}
 
 	trueWinnerTeam := g.UnderstandWinnerTeam()
@@ -134,9 +161,26 @@ func (g *Game) NewFinishLog(winnerTeam *roles.Team, isFool bool) FinishLog {
 		panic("WinnerTeam is not determined! The game can still turn around!!")
 	}
 
+	g.RLock()
+	defer g.RUnlock()
 	return FinishLog{
 		WinnerTeam:  winnerTeam,
 		IsFool:      false,
 		TotalNights: g.nightCounter,
+		Bots:        g.getBotIDs(),
+	}
+}
+
+// NewSoloFinishLog Gives the log, if the game was won by the role on its own.
+// The role is not checked again, use UnderstandSoloWinner to find it.
+func (g *Game) NewSoloFinishLog(winnerRole *roles.Role) FinishLog {
+	g.RLock()
+	defer g.RUnlock()
+	return FinishLog{
+		WinnerTeam:  nil,
+		IsFool:      winnerRole == roles.Fool,
+		SoloWinner:  winnerRole.Name,
+		TotalNights: g.nightCounter,
+		Bots:        g.getBotIDs(),
 	}
 }
@@ -13,7 +13,6 @@ import (
 	myFMT "github.com/https-whoyan/MafiaCore/fmt"
 	playerPack "github.com/https-whoyan/MafiaCore/player"
 	rolesPack "github.com/https-whoyan/MafiaCore/roles"
-	myTime "github.com/https-whoyan/MafiaCore/time"
 
 	"github.com/samber/lo"
 )
@@ -36,16 +35,31 @@ var (
 		}
 		return strings.ToUpper(string(s[0])) + strings.ToLower(s[1:])
 	}
-	getRandomPlayersCalling = func() string { return playersCalling[rand.Intn(len(playersCalling))] }
-	getRandomPlayerCalling  = func() string { return playerCalling[rand.Intn(len(playerCalling))] }
+	getRandomPlayersCalling = func(rnd *rand.Rand) string { return playersCalling[rnd.Intn(len(playersCalling))] }
+	getRandomPlayerCalling  = func(rnd *rand.Rand) string { return playerCalling[rnd.Intn(len(playerCalling))] }
 )
 
+// durationToString presents the duration in minutes, if it is a whole number of minutes, else in seconds.
+func durationToString(d time.Duration) string {
+	if d >= time.Minute && d%time.Minute == 0 {
+		minutes := int(d / time.Minute)
+		if minutes == 1 {
+			return "1 minute"
+		}
+		return sInt(minutes) + " minutes"
+	}
+	return sInt(int(math.Ceil(d.Seconds()))) + " seconds"
+}
+
 type Messenger struct {
 	f          myFMT.FmtInterface
 	Init       *initMessenger
 	Night      *nightMessenger
 	Day        *dayMessenger
 	AfterNight *afterNightMessenger
+	LastWords  *lastWordsMessenger
+	Left       *leftMessenger
+	Host       *hostMessenger
 	Finish     *finishMessenger
 	Public     *PublicMessanger
 }
@@ -68,6 +82,9 @@ func NewGameMessanger(f myFMT.FmtInterface, g *Game) *Messenger {
 		Night:      &nightMessenger{base},
 		Day:        &dayMessenger{base},
 		AfterNight: &afterNightMessenger{base},
+		LastWords:  &lastWordsMessenger{base},
+		Left:       &leftMessenger{base},
+		Host:       &hostMessenger{base},
 		Finish:     &finishMessenger{base},
 	}
 }
@@ -89,7 +106,7 @@ func (m initMessenger) SendStartMessage(writer io.Writer) error {
 	dNl := nl + nl
 	iL := f.InfoSplitter()
 
-	message = f.Bold("Have a good day, " + getRandomPlayersCalling() + "!")
+	message = f.Bold("Have a good day, " + getRandomPlayersCalling(m.g.rand) + "!")
 	message += dNl
 	message += myFMT.BoldUnderline(f, "Today, our players:") + nl
 
@@ -99,7 +116,7 @@ func (m initMessenger) SendStartMessage(writer io.Writer) error {
 		return activePlayers[j].ID < activePlayers[j].ID
 	})
 	for _, player := range activePlayers {
-		messageAboutPlayerID := f.Tab() + f.Bold(sCap(getRandomPlayerCalling())) + " " + f.Mention(player.ServerNick)
+		messageAboutPlayerID := f.Tab() + f.Bold(sCap(getRandomPlayerCalling(m.g.rand))) + " " + f.Mention(player.ServerNick)
 		messageAboutPlayerID += " with ID in game " + f.Block(sInt(int(player.ID)))
 
 		aboutIDMessages = append(aboutIDMessages, messageAboutPlayerID)
@@ -158,13 +175,13 @@ func (m *nightMessenger) SendInitialNightMessage(w io.Writer) error {
 	return m.sendMessage(message, w)
 }
 
-func (m *nightMessenger) SendInvitingToVoteMessage(p *playerPack.Player, deadlineInSeconds int, w io.Writer) error {
+func (m *nightMessenger) SendInvitingToVoteMessage(p *playerPack.Player, deadline time.Duration, w io.Writer) error {
 	m.g.RLock()
 	defer m.g.RUnlock()
 	f := m.f
 	message := f.Bold("Hello, " + f.Mention(p.ServerNick) + ". It's your turn to Vote.")
 	message += f.LineSplitter()
-	message += myFMT.BoldUnderline(f, fmt.Sprintf("Deadline: %v seconds.", deadlineInSeconds))
+	message += myFMT.BoldUnderline(f, "Deadline: "+durationToString(deadline)+".")
 	return m.sendMessage(message, w)
 }
 
@@ -223,8 +240,73 @@ func (m afterNightMessenger) SendAfterNightMessage(l NightLog, w io.Writer) erro
 	}
 	message += " which is to say: " + strings.Join(mentions, ", ")
 	message += f.LineSplitter() + f.LineSplitter()
-	message += f.Bold("Dear victims, you have " +
-		strconv.Itoa(myTime.LastWordDeadlineMinutes) + " minute to say your angry.")
+	message += f.Bold("Dear victims, each of you will have " +
+		durationToString(m.g.timings.LastWordDeadline) + " to say your angry.")
+	return m.sendMessage(message, w)
+}
+
+// ____________
+// LastWords
+// ____________
+
+type lastWordsMessenger struct {
+	*primitiveMessenger
+}
+
+func (m lastWordsMessenger) SendLastWordsMessage(w io.Writer, speaker *playerPack.Player, deadline time.Duration) error {
+	f := m.f
+	message := f.Bold("Last words of ") + f.Mention(speaker.ServerNick) + "."
+	message += f.LineSplitter()
+	message += "You have " + f.Block(durationToString(deadline)) + ", everyone else, please, keep silent."
+	return m.sendMessage(message, w)
+}
+
+func (m lastWordsMessenger) SendLastWordsAreOverMessage(w io.Writer) error {
+	message := m.f.Italic("The last words are said. Rest in peace...")
+	return m.sendMessage(message, w)
+}
+
+// ____________
+// Left
+// ____________
+
+type leftMessenger struct {
+	*primitiveMessenger
+}
+
+func (m leftMessenger) SendPlayerLeftMessage(w io.Writer, p *playerPack.Player) error {
+	message := m.f.Mention(p.ServerNick) + m.f.Bold(" has left the game.")
+	return m.sendMessage(message, w)
+}
+
+// ____________
+// Host
+// ____________
+
+type hostMessenger struct {
+	*primitiveMessenger
+}
+
+func (m hostMessenger) SendPausedMessage(w io.Writer) error {
+	return m.sendMessage(m.f.Bold("The game is paused by the host."), w)
+}
+
+func (m hostMessenger) SendResumedMessage(w io.Writer) error {
+	return m.sendMessage(m.f.Bold("The game is resumed."), w)
+}
+
+func (m hostMessenger) SendDeadlineExtendedMessage(w io.Writer, d time.Duration) error {
+	message := m.f.Bold("The host has given ") + m.f.Block(durationToString(d)) + m.f.Bold(" more.")
+	return m.sendMessage(message, w)
+}
+
+func (m hostMessenger) SendPlayerKilledMessage(w io.Writer, p *playerPack.Player) error {
+	message := m.f.Mention(p.ServerNick) + m.f.Bold(" is removed from the game by the host.")
+	return m.sendMessage(message, w)
+}
+
+func (m hostMessenger) SendPlayerRevivedMessage(w io.Writer, p *playerPack.Player) error {
+	message := m.f.Mention(p.ServerNick) + m.f.Bold(" is returned to the game by the host.")
 	return m.sendMessage(message, w)
 }
 
@@ -236,6 +318,12 @@ type dayMessenger struct {
 	*primitiveMessenger
 }
 
+// sendMessage also gives the message to the bots, they read the main channel as the people.
+func (m dayMessenger) sendMessage(msg string, writer io.Writer) error {
+	m.g.sendDayMessageToBots(msg)
+	return m.primitiveMessenger.sendMessage(msg, writer)
+}
+
 func (m dayMessenger) SendMessageAboutNewDay(w io.Writer, deadline time.Duration) error {
 	f := m.f
 
@@ -246,8 +334,60 @@ func (m dayMessenger) SendMessageAboutNewDay(w io.Writer, deadline time.Duration
 	message += f.LineSplitter()
 	message += f.LineSplitter()
 
-	message += f.Bold("Skip voting will be, if ") + f.Block(strconv.Itoa(DayPercentageToNextStage)+"%") +
-		" of player leave vote to skip."
+	switch m.g.dayVotingRules.Majority {
+	case AbsoluteDayMajority:
+		message += f.Bold("To be kicked, the player needs ") + f.Block("more than half") + " of the votes."
+	case TwoThirdsDayMajority:
+		message += f.Bold("To be kicked, the player needs ") + f.Block("two-thirds") + " of the votes."
+	default:
+		message += f.Bold("The player with ") + f.Block(strconv.Itoa(DayPercentageToNextStage)+"%") +
+			" of votes will be kicked at once."
+	}
+	if !m.g.dayVotingRules.IgnoreEmptyVotes {
+		message += f.LineSplitter()
+		message += f.Bold("Skip voting will be, if ") + "the empty votes are not fewer than the votes for the leader."
+	}
+	return m.sendMessage(message, w)
+}
+
+func (m dayMessenger) SendNominationMessage(w io.Writer, deadline time.Duration) error {
+	f := m.f
+
+	var message string
+	message += "Comes a " + f.Block(strconv.Itoa(m.g.nightCounter)) + " day. "
+	message += f.Bold("You have ") + f.Block(durationToString(deadline)) + " to nominate the suspects."
+	message += f.LineSplitter()
+	message += "Your vote is your nomination. If no one is nominated, the vote will be skipped."
+	return m.sendMessage(message, w)
+}
+
+func (m dayMessenger) SendDefenseMessage(w io.Writer, nominee *playerPack.Player, deadline time.Duration) error {
+	var message string
+	message = m.f.Mention(nominee.ServerNick) + ", " +
+		m.f.Bold("you have "+durationToString(deadline)+" to defend yourself.")
+	return m.sendMessage(message, w)
+}
+
+func (m dayMessenger) SendFinalVotingMessage(w io.Writer, candidates []playerPack.IDType,
+	isRunoff bool, deadline time.Duration) error {
+	f := m.f
+
+	var mentions []string
+	for _, candidateID := range candidates {
+		if p := m.g.active.GetByIDType(candidateID); p != nil {
+			mentions = append(mentions, f.Mention(p.ServerNick))
+		}
+	}
+
+	var message string
+	if isRunoff {
+		message += f.Bold("The votes are tied! Runoff vote between: ")
+	} else {
+		message += f.Bold("Final vote between: ")
+	}
+	message += strings.Join(mentions, ", ")
+	message += f.LineSplitter()
+	message += "You have " + f.Block(durationToString(deadline)) + " to set your votes."
 	return m.sendMessage(message, w)
 }
 
@@ -284,10 +424,10 @@ func (m finishMessenger) SendParticipantAboutMessage() string {
 	var message string
 	message = myFMT.BoldUnderline(f, "And the roles of the participants were:") + f.LineSplitter() + f.LineSplitter()
 
-	allPartitionsMp := m.g.active
-	allPartitionsMp.Append(m.g.dead.ConvertToPlayers())
+	allPartitionsMp := make(playerPack.Players)
+	allPartitionsMp.Append(m.g.active, m.g.dead.ConvertToPlayers())
 
-	allPartitionsSlice := lo.Values(*allPartitionsMp)
+	allPartitionsSlice := lo.Values(allPartitionsMp)
 
 	sort.Slice(allPartitionsSlice, func(i, j int) bool {
 		return allPartitionsSlice[i].ID < allPartitionsSlice[j].ID
@@ -306,9 +446,12 @@ func (m finishMessenger) SendParticipantAboutMessage() string {
 
 func (m finishMessenger) SendMessagesAboutEndOfGame(l FinishLog, w io.Writer) error {
 	var message string
-	if l.IsFool {
+	switch {
+	case l.IsFool:
 		message = m.getFoolWinnerMessage()
-	} else {
+	case l.SoloWinner != "":
+		message = m.getSoloWinnerMessage(l.SoloWinner)
+	default:
 		message = m.getTeamWinnerMessage(*l.WinnerTeam)
 	}
 	return m.sendMessage(message, w)
@@ -331,17 +474,37 @@ func (m finishMessenger) getFoolWinnerMessage() string {
 		"The fool's goal is to get ousted during the day's voting."
 	message += m.f.LineSplitter()
 
-	// Search fool
-	var fool = &playerPack.Player{}
+	message += "Fool in this game was: " + m.getRoleMentions(rolesPack.Fool)
+	message += m.f.LineSplitter()
+	message += "Nice try!"
+	return message
+}
+
+func (m finishMessenger) getSoloWinnerMessage(roleName string) string {
+	var message = m.basicEndGameMessage()
+
+	message += m.f.Bold("This game was won by the ") + m.f.Block(roleName) + m.f.Bold(" alone!")
+	message += m.f.LineSplitter()
+
+	role, _ := rolesPack.GetRoleByName(roleName)
+	message += roleName + " in this game was: " + m.getRoleMentions(role)
+	message += m.f.LineSplitter()
+	message += "Nice try!"
+	return message
+}
+
+// getRoleMentions returns mentions of all players (alive or dead) who played the role.
+func (m finishMessenger) getRoleMentions(role *rolesPack.Role) string {
+	var mentions []string
 	for _, p := range *m.g.active {
-		if p.Role == rolesPack.Fool {
-			fool = p
+		if p.Role == role {
+			mentions = append(mentions, m.f.Mention(p.ServerNick))
 		}
 	}
-	foolMentions := m.f.Mention(fool.ServerNick)
-	message += "Fool in this game was: " + foolMentions
-	message += "Nice try!"
-	return message
+	for _, p := range (*m.g.dead)[role] {
+		mentions = append(mentions, m.f.Mention(p.ServerNick))
+	}
+	return strings.Join(mentions, ", ")
 }
 
 type PublicMessanger struct {
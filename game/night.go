@@ -1,13 +1,14 @@
 package game
 
 import (
+	"errors"
+	"slices"
 	"sort"
 	"time"
 
 	channelPack "github.com/https-whoyan/MafiaCore/channel"
 	playerPack "github.com/https-whoyan/MafiaCore/player"
 	rolesPack "github.com/https-whoyan/MafiaCore/roles"
-	myTime "github.com/https-whoyan/MafiaCore/time"
 )
 
 // Night
@@ -19,7 +20,11 @@ func (g *Game) Night() NightLog {
 		return NightLog{}
 	default:
 		g.SetState(NightState)
-		g.infoSender <- g.newSwitchStateSignal()
+		g.Lock()
+		g.nightTurns = make(map[*rolesPack.Role]*nightTurn)
+		g.nightVotesStart = newNightVotesStart(g.active)
+		g.Unlock()
+		g.sendInfo(g.newSwitchStateSignal())
 
 		err := g.messenger.Night.SendInitialNightMessage(g.mainChannel)
 		safeSendErrSignal(g.errSender, err)
@@ -39,14 +44,16 @@ func (g *Game) Night() NightLog {
 		// I hereby signify that the voting is over.
 		g.Lock()
 		g.nightVoting = nil
+		g.recordVotingRoleSwitched()
 		g.Unlock()
 		g.RLock()
 
 		// I do the rest of the interactions that come after the vote.
+		// The decision of the role is applied once, by the executor of its turn.
 		var needToProcessPlayers []*playerPack.Player
-		for _, p := range *g.active {
-			if p.Role.CalculationOrder > 0 && !p.Role.UrgentCalculation {
-				needToProcessPlayers = append(needToProcessPlayers, p)
+		for role, turn := range g.nightTurns {
+			if role.CalculationOrder > 0 && !role.UrgentCalculation && turn.executor != nil {
+				needToProcessPlayers = append(needToProcessPlayers, turn.executor)
 			}
 		}
 		g.RUnlock()
@@ -79,8 +86,10 @@ func (g *Game) RoleNightAction(votedRole *rolesPack.Role) {
 
 		g.Lock()
 		g.nightVoting = votedRole
+		g.nightTurns[votedRole] = newNightTurn()
+		g.recordVotingRoleSwitched()
 		g.Unlock()
-		g.infoSender <- g.newSwitchVotingRoleSignal()
+		g.sendInfo(g.newSwitchVotingRoleSignal())
 		// Finding all the players with that role.
 		// And finding nightInteraction channel
 		g.RLock()
@@ -88,67 +97,7 @@ func (g *Game) RoleNightAction(votedRole *rolesPack.Role) {
 		allPlayersWithRole := g.active.SearchAllPlayersWithRole(votedRole)
 		g.RUnlock()
 
-		var (
-			nonEmptyVote1 playerPack.IDType = EmptyVoteInt
-			nonEmptyVote2 playerPack.IDType = EmptyVoteInt
-		)
-
-		sendToOtherEmptyVotes := func(nonEmptyVoter *playerPack.Player) {
-			voterLen := len(nonEmptyVoter.Votes)
-			if votedRole.IsTwoVotes {
-				nonEmptyVote1 = nonEmptyVoter.Votes[voterLen-2]
-				nonEmptyVote2 = nonEmptyVoter.Votes[voterLen-1]
-			} else {
-				nonEmptyVote1 = nonEmptyVoter.Votes[voterLen-1]
-			}
-
-			// Set to other empty votes
-			for _, playerWithRole := range *allPlayersWithRole {
-				if playerWithRole == nonEmptyVoter {
-					continue
-				}
-				if votedRole.IsTwoVotes {
-					playerWithRole.Votes = append(playerWithRole.Votes, nonEmptyVote1, nonEmptyVote2)
-				} else {
-					playerWithRole.Votes = append(playerWithRole.Votes, nonEmptyVote1)
-				}
-			}
-			return
-		}
-		findOrStandNotEmptyVoter := func() (nonEmptyVoter *playerPack.Player) {
-			// Need to find a not empty Vote.
-			for _, voter := range *allPlayersWithRole {
-				voterVotesLen := len(voter.Votes)
-				if voterVotesLen == 0 {
-					continue
-				}
-				if voter.Votes[voterVotesLen-1] == EmptyVoteInt {
-					continue
-				}
-				nonEmptyVoter = voter
-				break
-			}
-
-			// If deadline pass, or player set EmptyVote, stand nonEmptyVoter
-			// on somebody, and we'll put votes on the player.
-			if nonEmptyVoter == nil {
-				for _, p := range *allPlayersWithRole {
-					nonEmptyVoter = p
-
-					if votedRole.IsTwoVotes {
-						p.Votes = append(p.Votes, nonEmptyVote1, nonEmptyVote2)
-					} else {
-						p.Votes = append(p.Votes, nonEmptyVote1)
-					}
-
-					break
-				}
-			}
-			return
-		}
-
-		voteDeadlineInt := myTime.VotingDeadline
-		voteDeadline := time.Second * time.Duration(voteDeadlineInt)
+		voteDeadline := g.timings.GetVotingDeadline(votedRole.Name)
 
 		containsNotMutedPlayers := false
 
@@ -165,23 +114,27 @@ func (g *Game) RoleNightAction(votedRole *rolesPack.Role) {
 
 			} else {
 				containsNotMutedPlayers = true
-				err = g.messenger.Night.SendInvitingToVoteMessage(voter, voteDeadlineInt, interactionChannel)
+				err = g.messenger.Night.SendInvitingToVoteMessage(voter, voteDeadline, interactionChannel)
 				safeSendErrSignal(g.errSender, err)
 			}
 		}
+		g.inviteBotToNightVote(votedRole)
 
-		// From this differs in which channel the game will wait for the voice,
-		//as well as the difference in the voice itself.
-		var isTimerStop bool
-		switch !votedRole.IsTwoVotes {
-		case true:
-			isTimerStop = g.oneVoteRoleNightVoting(containsNotMutedPlayers, voteDeadline)
-		case false:
-			isTimerStop = g.twoVoterRoleNightVoting(containsNotMutedPlayers, voteDeadline)
-		}
+		isTimerStop := g.nightTurnVoting(containsNotMutedPlayers, voteDeadline)
+		g.Lock()
+		g.nightTurns[votedRole].isClosed = true
+		g.Unlock()
 
 		if isTimerStop && containsNotMutedPlayers {
 			safeSendErrSignal(g.errSender, g.messenger.Night.InfoThatTimerIsDone(interactionChannel))
+			g.Lock()
+			for _, voter := range *allPlayersWithRole {
+				_, isVoted := g.nightTurns[votedRole].votes[voter.ID]
+				if voter.InteractionStatus != playerPack.Muted && !isVoted {
+					g.markMissed(voter.ID)
+				}
+			}
+			g.Unlock()
 		}
 
 		// Putting it back in the channel.
@@ -195,89 +148,141 @@ func (g *Game) RoleNightAction(votedRole *rolesPack.Role) {
 			}
 		}
 
-		nonEmptyVoter := findOrStandNotEmptyVoter()
-		sendToOtherEmptyVotes(nonEmptyVoter)
+		executor := g.resolveNightTurn(votedRole)
+		// The role has decided nothing, or all players with the role are already out.
+		if executor == nil {
+			return
+		}
 
 		// Case when roles need to urgent calculation
 		g.infoLogger.Println(votedRole.Name, votedRole.UrgentCalculation)
 		if votedRole.UrgentCalculation {
-			message := g.nightInteraction(nonEmptyVoter)
+			message := g.nightInteraction(executor)
 			if message != nil {
 				_, err = interactionChannel.Write([]byte(*message))
 				safeSendErrSignal(g.errSender, err)
 			}
+			g.sendNightResultToBots(executor, message)
 		}
 	}
 }
 
 /*
-	The logic of accepting a role's Vote, and timers,
-	depending on whether the role votes with 2 votes or one.
+	The logic of accepting a role's Vote, and timers.
 */
 
-func (g *Game) waitOneVoteRoleFakeTimer() {
-	g.randomTimer()
+// nightTurn is the night voting of the role.
+// SetNightVote and SetNightTwoVote put the votes in it and do not wait for the game,
+// the game decides, when the turn is complete.
+//
+// Used under the game lock.
+type nightTurn struct {
+	// Key - voter ID, value - his votes in the turn.
+	votes map[playerPack.IDType][]playerPack.IDType
+	// Voters in order of their current votes.
+	order []playerPack.IDType
+	// Voters, who vote as the Leader of the role (see TeamVotePolicy).
+	isLeader map[playerPack.IDType]bool
+	// Gets the notification of the accepted vote.
+	voted    chan struct{}
+	isClosed bool
+
+	// Set by resolveNightTurn.
+	decision []playerPack.IDType
+	executor *playerPack.Player
+}
 
-	select {
-	case <-g.timerDone:
-		break
-	case <-g.ctx.Done():
-		break
+func newNightTurn() *nightTurn {
+	return &nightTurn{
+		votes:    make(map[playerPack.IDType][]playerPack.IDType),
+		isLeader: make(map[playerPack.IDType]bool),
+		voted:    make(chan struct{}, 1),
 	}
 }
 
-func (g *Game) oneVoteRoleNightVoting(containsNotMutedPlayers bool, deadline time.Duration) (isTimerStop bool) {
-	if !containsNotMutedPlayers {
-		g.waitOneVoteRoleFakeTimer()
-		return
+// setVotes used under the game lock. Returns true, if the previous votes of the voter are superseded.
+func (t *nightTurn) setVotes(voterID playerPack.IDType, votes []playerPack.IDType) (isSuperseded bool) {
+	_, isSuperseded = t.votes[voterID]
+	if isSuperseded {
+		t.order = slices.DeleteFunc(t.order, func(id playerPack.IDType) bool { return id == voterID })
 	}
+	t.votes[voterID] = votes
+	t.order = append(t.order, voterID)
+	return
+}
 
-	g.timer(deadline)
-
+func (t *nightTurn) notify() {
 	select {
-	case <-g.voteAccepted:
-		g.timerStop <- struct{}{}
-		break
-	case <-g.timerDone:
-		isTimerStop = true
-		break
-	case <-g.ctx.Done():
-		break
+	case t.voted <- struct{}{}:
+	default:
 	}
-	return
 }
 
-func (g *Game) waitTwoVoteRoleFakeTimer() {
-	g.randomTimer()
+// lateNightVoteErr returns VoteTooLateErr instead of IncorrectVoteTimeErr,
+// if the turn of the role of the voter is over.
+func (g *Game) lateNightVoteErr(err error, vP interface{ GetVotedPlayerID() (string, bool) }) error {
+	if !errors.Is(err, IncorrectVoteTimeErr) {
+		return err
+	}
+	voterID, isServerID := vP.GetVotedPlayerID()
+	g.RLock()
+	defer g.RUnlock()
+	voter := g.active.SearchPlayerByID(voterID, isServerID)
+	if voter == nil {
+		return err
+	}
+	if turn, ok := g.nightTurns[voter.Role]; ok && turn.isClosed {
+		return VoteTooLateErr
+	}
+	return err
+}
+
+// waitFakeNightTurn is the turn of the role, whose players are all muted.
+func (g *Game) waitFakeNightTurn(deadline time.Duration) {
+	timer := g.newFakePhaseTimer(deadline)
+	defer g.stopPhaseTimer(timer)
 
 	select {
-	case <-g.timerDone:
+	case <-timer.C():
 		break
 	case <-g.ctx.Done():
 		break
 	}
 }
 
-func (g *Game) twoVoterRoleNightVoting(containsNotMutedPlayers bool, deadline time.Duration) (isTimerStop bool) {
+// nightTurnVoting waits, until the turn of the current role is complete, or for the deadline.
+func (g *Game) nightTurnVoting(containsNotMutedPlayers bool, deadline time.Duration) (isTimerStop bool) {
 	if !containsNotMutedPlayers {
-		g.waitTwoVoteRoleFakeTimer()
+		g.waitFakeNightTurn(deadline)
 		return
 	}
+	g.RLock()
+	role := g.nightVoting
+	turn := g.nightTurns[role]
+	g.RUnlock()
 
-	g.timer(deadline)
+	timer := g.newPhaseTimer(deadline)
+	defer g.stopPhaseTimer(timer)
 
-	select {
-	case <-g.voteAccepted:
-		g.infoLogger.Println("two vote accepted")
-		g.timerStop <- struct{}{}
-		break
-	case <-g.timerDone:
-		isTimerStop = true
-		break
-	case <-g.ctx.Done():
-		break
+	for {
+		select {
+		case <-turn.voted:
+			if g.isNightTurnComplete(role, turn) {
+				return
+			}
+		case <-timer.C():
+			// The skipped voting ends as if the role has voted,
+			// and the votes, accepted before the deadline, may complete the turn.
+			if g.isSkipped(timer) || g.isNightTurnComplete(role, turn) {
+				return
+			}
+			isTimerStop = true
+			g.timerExpired()
+			return
+		case <-g.ctx.Done():
+			return
+		}
 	}
-	return
 }
 
 // AffectNight changes players according to the night's actions.
@@ -296,17 +301,18 @@ func (g *Game) AffectNight(l NightLog) {
 		g.ResetAllInteractionsStatuses()
 		g.Lock()
 
-		// Splitting arrays.
-		var newDeadPersons = &playerPack.Players{}
-
 		for _, deadID := range l.Dead {
 			g.active.ToDead(deadID, playerPack.KilledAtNight, g.nightCounter, g.dead)
-			newDeadPersons.Append(g.active)
+			died := PlayerDiedData{
+				PlayerID:  deadID,
+				Reason:    playerPack.KilledAtNight,
+				LivedDays: g.nightCounter,
+			}
+			g.record(PlayerDiedEvent, died)
+			g.sendPlayerDied(died)
 		}
-
-		// I will add add add all killed players after a minute of players a minute of
-		// players after a minute, so, using goroutine.
-		go g.AppendToSpectators(newDeadPersons, myTime.LastWordDeadline*time.Second)
+		g.sendInfo(g.newInfoSignal(NightResultSignal, NightResultInfo{Log: l}))
+		// The dead players are moved to the spectators after their last words (see LastWords).
 
 		// Sending a message about who died today.
 		err := g.messenger.AfterNight.SendAfterNightMessage(l, g.mainChannel)
@@ -321,38 +327,42 @@ func (g *Game) AffectNight(l NightLog) {
 }
 
 func (g *Game) AppendToSpectators(newSpectators interface{ GetTags() []string }, after time.Duration) {
-	ticker := time.NewTicker(after)
+	timer := g.timer(after)
+	defer timer.Stop()
+	select {
+	case <-g.ctx.Done():
+		return
+	case <-timer.C():
+		g.moveToSpectators(newSpectators)
+	}
+}
 
+func (g *Game) moveToSpectators(newSpectators interface{ GetTags() []string }) {
 	g.RLock()
 	mainChannel := g.mainChannel
 	roleChannels := g.roleChannels
 	g.RUnlock()
 
-	defer ticker.Stop()
-	select {
-	case <-g.ctx.Done():
-		return
-	case <-ticker.C:
-		// I'm adding new dead players to the spectators in the channels (so they won't be so bored)
-		for _, tag := range newSpectators.GetTags() {
-			for _, interactionChannel := range roleChannels {
-				select {
-				case <-g.ctx.Done():
-					return
-				default:
-					err := channelPack.FromUserToSpectator(interactionChannel, tag)
-					safeSendErrSignal(g.errSender, err)
-					break
-				}
-			}
+	// I'm adding new dead players to the spectators in the channels (so they won't be so bored)
+	for _, tag := range newSpectators.GetTags() {
+		for _, interactionChannel := range roleChannels {
 			select {
 			case <-g.ctx.Done():
 				return
 			default:
-				err := channelPack.FromUserToSpectator(mainChannel, tag)
+				err := channelPack.FromUserToSpectator(interactionChannel, tag)
 				safeSendErrSignal(g.errSender, err)
 				break
 			}
 		}
+		select {
+		case <-g.ctx.Done():
+			return
+		default:
+			err := channelPack.FromUserToSpectator(mainChannel, tag)
+			safeSendErrSignal(g.errSender, err)
+			break
+		}
 	}
+	g.record(SpectatorsMovedEvent, SpectatorsMovedData{Tags: newSpectators.GetTags()})
 }
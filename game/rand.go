@@ -0,0 +1,42 @@
+package game
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// All randomness of the game (role deal, fake vote timers, message flavour)
+// comes from one source, created by the seed.
+// So, the game can be reproduced from the seed (see RandSeedOpt and DeepCloneGame Seed).
+
+// lockedSource is a goroutine-safe rand.Source.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func newLockedSource(seed int64) *lockedSource {
+	return &lockedSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+func newRand(seed int64) *rand.Rand {
+	return rand.New(newLockedSource(seed))
+}
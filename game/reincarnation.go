@@ -2,45 +2,30 @@ package game
 
 import (
 	"github.com/https-whoyan/MafiaCore/player"
-	"github.com/https-whoyan/MafiaCore/roles"
-	"github.com/samber/lo"
+	"github.com/https-whoyan/MafiaCore/registry"
 )
 
 // This is where all the code regarding reincarnation and role reversal is contained.
 // This may be necessary if a role is specified to become a different role in certain scenarios.
+//
+// See registry.RoleBehavior Reincarnation.
 
 func (g *Game) reincarnation(p *player.Player) {
-	switch p.Role {
-	case roles.Don:
-		g.donReincarnation(p)
-	}
-	return
-}
-
-func (g *Game) donReincarnation(p *player.Player) {
-	// I find out he's the only one left on the mafia team.
-	g.RLock()
-
-	mafiaTeamCounter := lo.CountValues(lo.Map(
-		lo.Values(*g.active),
-		func(p *player.Player, _ int) roles.Team {
-			return p.Role.Team
-		}),
-	)[roles.MafiaTeam]
-	if mafiaTeamCounter > 1 {
-		g.RUnlock()
-		return
-	}
-	p.Role = roles.Mafia
-	safeSendErrSignal(g.errSender, g.roleChannels[roles.Don].RemoveUser(p.Tag))
-	safeSendErrSignal(g.errSender, g.roleChannels[roles.Mafia].AddPlayer(p.Tag))
-
-	f := g.messenger.f
-	g.RUnlock()
-	var message string
-	message = f.Bold("Hello, dear ") + f.Mention(p.ServerNick) + "." + f.LineSplitter()
-	message += "You are the last player left alive from the mafia team, so you become mafia." + f.LineSplitter()
-	message += f.Underline("Don't reveal yourself.")
-	_, err := g.roleChannels[roles.Mafia].Write([]byte(message))
+	g.Lock()
+	defer g.Unlock()
+	previousRole := p.Role
+	err := registry.GetBehavior(p.Role).Reincarnation(g.env(), p)
 	safeSendErrSignal(g.errSender, err)
+	if p.Role != previousRole {
+		g.record(RoleSwitchedEvent, RoleSwitchedData{
+			PlayerID:     p.ID,
+			FromRoleName: previousRole.Name,
+			ToRoleName:   p.Role.Name,
+		})
+		g.sendInfo(g.newInfoSignal(RoleChangedSignal, RoleChangedInfo{
+			PlayerID: p.ID,
+			FromRole: previousRole,
+			ToRole:   p.Role,
+		}))
+	}
 }
@@ -0,0 +1,224 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/LastPossum/kamino"
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+	rolesPack "github.com/https-whoyan/MafiaCore/roles"
+)
+
+// Replay errors.
+var (
+	EmptyJournalErr         = errors.New("empty journal")
+	JournalIsNotStartedErr  = errors.New("journal is not started with GameStartedEvent or GameRestoredEvent")
+	UnknownEventErr         = errors.New("unknown event")
+	ReplayPlayerNotFoundErr = errors.New("player of the event not found")
+)
+
+// Replay rebuilds the game by the events of the journal (see Game.GetEvents).
+//
+// To get the game at the some point, pass only the events before it,
+// for example, Replay(events[:n+1]) gives the game right after the event with Seq n.
+//
+// Channels are not journaled, so RoleChannels and MainChannel are always empty.
+func Replay(events []Event) (DeepCloneGame, error) {
+	if len(events) == 0 {
+		return DeepCloneGame{}, EmptyJournalErr
+	}
+	var (
+		g   DeepCloneGame
+		err error
+	)
+	switch data := events[0].Data.(type) {
+	case GameStartedData:
+		g, err = newReplayedGame(data)
+	case GameRestoredData:
+		g, err = newRestoredReplayedGame(data)
+	default:
+		return DeepCloneGame{}, JournalIsNotStartedErr
+	}
+	if err != nil {
+		return DeepCloneGame{}, err
+	}
+	for _, e := range events[1:] {
+		if err = g.applyEvent(e); err != nil {
+			return g, fmt.Errorf("event %v: %w", e.Seq, err)
+		}
+	}
+	return g, nil
+}
+
+func newReplayedGame(data GameStartedData) (DeepCloneGame, error) {
+	startPlayers := playerPack.NonPlayingPlayers{}
+	for _, p := range data.StartPlayers {
+		startPlayer := p
+		startPlayers = append(startPlayers, &startPlayer)
+	}
+	spectators := playerPack.NonPlayingPlayers{}
+	for _, p := range data.Spectators {
+		spectator := p
+		spectators = append(spectators, &spectator)
+	}
+	active := make(playerPack.Players)
+	for _, p := range data.Players {
+		role, ok := rolesPack.GetRoleByName(p.RoleName)
+		if !ok {
+			return DeepCloneGame{}, fmt.Errorf("%w: %v", UnknownRoleErr, p.RoleName)
+		}
+		active[p.ID] = &playerPack.Player{
+			NonPlayingPlayer: p.NonPlayingPlayer,
+			ID:               p.ID,
+			Role:             role,
+		}
+	}
+	dead := make(playerPack.DeadPlayers)
+
+	var playersCount int
+	if data.RolesConfig != nil {
+		playersCount = data.RolesConfig.PlayersCount
+	}
+	g := DeepCloneGame{
+		GuildID:         data.GuildID,
+		PlayersCount:    playersCount,
+		RolesConfig:     data.RolesConfig,
+		TimeStart:       data.TimeStart,
+		StartPlayers:    &startPlayers,
+		Active:          &active,
+		Dead:            &dead,
+		Spectators:      &spectators,
+		VoteForYourself: data.VoteForYourself,
+		VotePing:        data.VotePing,
+		PreviousState:   data.PreviousState,
+		State:           data.State,
+		RenameMode:      data.RenameMode,
+		NightLogs:       make([]NightLog, 0),
+		DayLogs:         make([]DayLog, 0),
+		Seed:            data.Seed,
+	}
+	// The roles of the decoded events are copies.
+	return g, g.resolveRoles()
+}
+
+func newRestoredReplayedGame(data GameRestoredData) (DeepCloneGame, error) {
+	g, err := kamino.Clone(data.Game)
+	if err != nil {
+		return DeepCloneGame{}, err
+	}
+	if err = g.resolveRoles(); err != nil {
+		return DeepCloneGame{}, err
+	}
+	return g, nil
+}
+
+func (g *DeepCloneGame) getPlayer(id playerPack.IDType) (*playerPack.Player, error) {
+	p := g.Active.GetByIDType(id)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %v", ReplayPlayerNotFoundErr, id)
+	}
+	return p, nil
+}
+
+func (g *DeepCloneGame) applyEvent(e Event) error {
+	switch data := e.Data.(type) {
+	case StateSwitchedData:
+		g.NightCounter = data.NightCounter
+		g.PreviousState = data.PreviousState
+		g.State = data.NewState
+		if data.NewState == NightState {
+			g.NightVotesStart = newNightVotesStart(g.Active)
+		}
+	case VotingRoleSwitchedData:
+		g.NightVoting = nil
+		if data.RoleName == "" {
+			return nil
+		}
+		role, ok := rolesPack.GetRoleByName(data.RoleName)
+		if !ok {
+			return fmt.Errorf("%w: %v", UnknownRoleErr, data.RoleName)
+		}
+		g.NightVoting = role
+	case NightVoteData:
+		p, err := g.getPlayer(data.VoterID)
+		if err != nil {
+			return err
+		}
+		if data.IsLeader {
+			return nil
+		}
+		if data.IsSuperseded && len(p.Votes) >= len(data.Votes) {
+			p.Votes = p.Votes[:len(p.Votes)-len(data.Votes)]
+		}
+		p.Votes = append(p.Votes, data.Votes...)
+	case DayVoteData:
+		p, err := g.getPlayer(data.VoterID)
+		if err != nil {
+			return err
+		}
+		p.DayVote = data.Vote
+	case DayVoteWithdrawnData:
+		p, err := g.getPlayer(data.VoterID)
+		if err != nil {
+			return err
+		}
+		p.DayVote = EmptyVoteInt
+	case PlayerStatusChangedData:
+		p, err := g.getPlayer(data.PlayerID)
+		if err != nil {
+			return err
+		}
+		p.LifeStatus = data.LifeStatus
+		p.InteractionStatus = data.InteractionStatus
+	case InteractionsResetData:
+		for _, p := range *g.Active {
+			p.InteractionStatus = playerPack.Passed
+		}
+	case DayVotesClearedData:
+		for _, p := range *g.Active {
+			p.DayVote = EmptyVoteInt
+		}
+	case RoleSwitchedData:
+		p, err := g.getPlayer(data.PlayerID)
+		if err != nil {
+			return err
+		}
+		role, ok := rolesPack.GetRoleByName(data.ToRoleName)
+		if !ok {
+			return fmt.Errorf("%w: %v", UnknownRoleErr, data.ToRoleName)
+		}
+		p.Role = role
+	case PlayerDiedData:
+		if _, err := g.getPlayer(data.PlayerID); err != nil {
+			return err
+		}
+		g.Active.ToDead(data.PlayerID, data.Reason, data.LivedDays, g.Dead)
+	case PlayerReplacedData:
+		p, err := g.getPlayer(data.PlayerID)
+		if err != nil {
+			return err
+		}
+		p.NonPlayingPlayer = data.User
+		for i, startPlayer := range *g.StartPlayers {
+			if startPlayer.Tag == data.OldTag {
+				user := data.User
+				(*g.StartPlayers)[i] = &user
+			}
+		}
+	case PlayerRevivedData:
+		if g.Dead.ToAlive(data.PlayerID, g.Active) == nil {
+			return fmt.Errorf("%w: %v", ReplayPlayerNotFoundErr, data.PlayerID)
+		}
+	case NightFinishedData:
+		g.NightLogs = append(g.NightLogs, data.Log)
+	case DayFinishedData:
+		g.DayLogs = append(g.DayLogs, data.Log)
+	case GameFinishedData:
+		g.EndTime = data.EndTime
+	case VoteRejectedData, TimerExpiredData, SpectatorsMovedData, DayPhaseSwitchedData, HostActionData:
+		// Do not change the game.
+	default:
+		return fmt.Errorf("%w: %v", UnknownEventErr, e.EventType)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/LastPossum/kamino"
+	channelPack "github.com/https-whoyan/MafiaCore/channel"
+	configPack "github.com/https-whoyan/MafiaCore/config"
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+	rolesPack "github.com/https-whoyan/MafiaCore/roles"
+)
+
+// This file contains the restoring of the game from its DeepCloneGame,
+// for example, after the restart of the process.
+
+// Restore errors.
+var (
+	NotRestorableStateErr = errors.New("game in this state can not be restored")
+	UnknownRoleErr        = errors.New("unknown role")
+)
+
+/*
+Restore creates the game by its snapshot (see Storage, Game.GetDeepClone).
+
+The snapshots saved by Storage are made on the phase boundaries,
+so the restored game continues from the next phase: the day after the night and the night after the day.
+If the snapshot was made in the middle of the night (or the day), the phase is played again from the beginning.
+
+Channels can not be saved, so you need to pass them again.
+Players are not added to channels and are not renamed again, because it was made before the restart.
+
+Options of the game (except the snapshot fields) are not saved too, so pass them again.
+
+Use Run after Restore.
+*/
+func Restore(ctx context.Context, clone DeepCloneGame,
+	mainChannel channelPack.MainChannel, roleChannels []channelPack.RoleChannel, opts ...Option) (*Game, error) {
+	// The channels are passed again, the live ones of the snapshot must not be copied.
+	clone.MainChannel, clone.RoleChannels = nil, nil
+	clone, err := kamino.Clone(clone)
+	if err != nil {
+		return nil, err
+	}
+	if err = clone.resolveRoles(); err != nil {
+		return nil, err
+	}
+
+	defaultOpts := []Option{
+		VotePingOpt(clone.VotePing),
+		VoteForYourselfOpt(clone.VoteForYourself),
+		RenameModeOpt(clone.RenameMode),
+		RandSeedOpt(clone.Seed),
+	}
+	g := GetNewGame(ctx, clone.GuildID, append(defaultOpts, opts...)...)
+	if err = g.SetMainChannel(mainChannel); err != nil {
+		return nil, err
+	}
+	if err = g.SetRoleChannels(roleChannels...); err != nil {
+		return nil, err
+	}
+
+	g.Lock()
+	g.playersCount = clone.PlayersCount
+	g.nightCounter = clone.NightCounter
+	g.timeStart = clone.TimeStart
+	g.startPlayers = clone.StartPlayers
+	g.active = clone.Active
+	g.dead = clone.Dead
+	g.spectators = clone.Spectators
+	g.nightVotesStart = clone.NightVotesStart
+	g.nightLogs = append(g.nightLogs, clone.NightLogs...)
+	g.dayLogs = append(g.dayLogs, clone.DayLogs...)
+	if g.startPlayers == nil {
+		g.startPlayers = &playerPack.NonPlayingPlayers{}
+	}
+	if g.active == nil {
+		g.active = &playerPack.Players{}
+	}
+	if g.dead == nil {
+		g.dead = &playerPack.DeadPlayers{}
+	}
+	if g.spectators == nil {
+		g.spectators = &playerPack.NonPlayingPlayers{}
+	}
+	g.Unlock()
+
+	if err = g.validationStart(clone.RolesConfig); err != nil {
+		return nil, err
+	}
+
+	g.Lock()
+	defer g.Unlock()
+	g.rolesConfig = clone.RolesConfig
+	if g.restoredPhase, err = g.rollbackToPhaseBoundary(clone.State, clone.PreviousState); err != nil {
+		return nil, err
+	}
+	// Run starts only not running games.
+	g.previousState = clone.State
+	g.state = StartingState
+
+	restored, err := g.getUnlockedDeepClone()
+	if err != nil {
+		return nil, err
+	}
+	g.record(GameRestoredEvent, GameRestoredData{Game: restored})
+	return g, nil
+}
+
+// rollbackToPhaseBoundary cancels the unfinished phase and returns the phase, from which the game continues.
+//
+// Used under the game lock.
+func (g *Game) rollbackToPhaseBoundary(state, previousState State) (State, error) {
+	switch state {
+	case StartingState:
+		return NightState, nil
+	case NightState:
+		if len(g.nightLogs) >= g.nightCounter {
+			return DayState, nil
+		}
+		// The night is not over. All of its votes and interactions are canceled.
+		g.nightCounter--
+		for _, p := range *g.active {
+			if start, ok := g.nightVotesStart[p.ID]; ok {
+				p.Votes = p.Votes[:min(len(p.Votes), start)]
+			}
+			p.LifeStatus = playerPack.Alive
+			p.InteractionStatus = playerPack.Passed
+		}
+		return NightState, nil
+	case DayState:
+		for _, p := range *g.active {
+			p.DayVote = EmptyVoteInt
+		}
+		if len(g.dayLogs) >= g.nightCounter {
+			return NightState, nil
+		}
+		return DayState, nil
+	case LastWordsState:
+		// The last words are lost, the game continues from the next phase.
+		if previousState == NightState {
+			return DayState, nil
+		}
+		return NightState, nil
+	default:
+		return "", fmt.Errorf("%w: %v", NotRestorableStateErr, state)
+	}
+}
+
+// getUnlockedDeepClone same as GetDeepClone, but without channels.
+//
+// Used under the game lock.
+func (g *Game) getUnlockedDeepClone() (DeepCloneGame, error) {
+	return kamino.Clone(DeepCloneGame{
+		GuildID:         g.guildID,
+		PlayersCount:    g.playersCount,
+		RolesConfig:     g.rolesConfig,
+		NightCounter:    g.nightCounter,
+		TimeStart:       g.timeStart,
+		EndTime:         g.endTime,
+		StartPlayers:    g.startPlayers,
+		Active:          g.active,
+		Dead:            g.dead,
+		Spectators:      g.spectators,
+		NightVoting:     g.nightVoting,
+		NightVotesStart: g.nightVotesStart,
+		VoteForYourself: g.voteForYourself,
+		VotePing:        g.votePing,
+		PreviousState:   g.previousState,
+		State:           g.state,
+		RenameMode:      g.renameMode,
+		NightLogs:       g.nightLogs,
+		DayLogs:         g.dayLogs,
+		Seed:            g.seed,
+	})
+}
+
+// newNightVotesStart returns the counts of the votes of the players at the start of the night.
+func newNightVotesStart(active *playerPack.Players) map[playerPack.IDType]int {
+	votesStart := make(map[playerPack.IDType]int)
+	if active == nil {
+		return votesStart
+	}
+	for _, p := range *active {
+		votesStart[p.ID] = len(p.Votes)
+	}
+	return votesStart
+}
+
+// ____________________
+// Roles resolving
+// ____________________
+
+// resolveRole returns the role from rolesPack.MappedRoles with the same name.
+//
+// Clones of the game contains copies of the roles, but the game compares roles by pointers.
+func resolveRole(role *rolesPack.Role) (*rolesPack.Role, error) {
+	if role == nil {
+		return nil, nil
+	}
+	resolved, ok := rolesPack.GetRoleByName(role.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", UnknownRoleErr, role.Name)
+	}
+	return resolved, nil
+}
+
+// resolveRoles replaces all roles of the clone to the roles from rolesPack.MappedRoles.
+func (g *DeepCloneGame) resolveRoles() (err error) {
+	if g.NightVoting, err = resolveRole(g.NightVoting); err != nil {
+		return err
+	}
+	if g.RolesConfig != nil {
+		cfg := &configPack.RolesConfig{
+			PlayersCount: g.RolesConfig.PlayersCount,
+			RolesMp:      make(map[string]*configPack.RoleConfig),
+		}
+		for roleName, roleCfg := range g.RolesConfig.RolesMp {
+			role, resolveErr := resolveRole(roleCfg.Role)
+			if resolveErr != nil {
+				return resolveErr
+			}
+			cfg.RolesMp[roleName] = &configPack.RoleConfig{Role: role, Count: roleCfg.Count}
+		}
+		g.RolesConfig = cfg
+	}
+	if g.Active != nil {
+		for _, p := range *g.Active {
+			if p.Role, err = resolveRole(p.Role); err != nil {
+				return err
+			}
+		}
+	}
+	if g.Dead != nil {
+		dead := make(playerPack.DeadPlayers)
+		for _, deadPlayers := range *g.Dead {
+			for _, p := range deadPlayers {
+				if p.Role, err = resolveRole(p.Role); err != nil {
+					return err
+				}
+				dead[p.Role] = append(dead[p.Role], p)
+			}
+		}
+		g.Dead = &dead
+	}
+	return nil
+}
@@ -1,8 +1,11 @@
 package game
 
 import (
-	"github.com/https-whoyan/MafiaCore/roles"
+	"sync"
 	"time"
+
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
 )
 
 // _____________________
@@ -28,6 +31,9 @@ const (
 // InfoSignal
 // __________________
 
+// InfoSignal informs about everything, that happens in the game.
+//
+// The signals come in order of the events, but the game does not wait for you to read them.
 type InfoSignal struct {
 	InitialTime    time.Time
 	InfoSignalType InfoSignalType
@@ -40,9 +46,26 @@ const (
 	SwitchStateSignal InfoSignalType = iota
 	SwitchVotingRoleSignal
 	FinishGameSignal
+	SwitchDayPhaseSignal
+	LastWordsSignal
+	PlayerAFKSignal
+	HostActionSignal
+	VoteCastSignal
+	VoteChangedSignal
+	PlayerDiedSignal
+	PlayerRevivedSignal
+	PlayerMutedSignal
+	RoleChangedSignal
+	TimerStartedSignal
+	TimerExpiredSignal
+	NightResultSignal
+	DayResultSignal
+	DayVoteTallyChangedSignal
 )
 
-// See SwitchStateInfo, SwitchVotingRoleInfo, FinishGameInfo
+// See SwitchStateInfo, SwitchVotingRoleInfo, FinishGameInfo, SwitchDayPhaseInfo, LastWordsInfo, PlayerAFKInfo,
+// HostActionInfo, VoteCastInfo, VoteChangedInfo, PlayerDiedInfo, PlayerRevivedInfo, PlayerMutedInfo, RoleChangedInfo,
+// TimerStartedInfo, TimerExpiredInfo, NightResultInfo, DayResultInfo, DayVoteTallyChangedInfo
 type infoSignalInterface interface {
 	infoSignalInterfacePrivateMethod()
 }
@@ -65,6 +88,147 @@ type FinishGameInfo struct{}
 
 func (FinishGameInfo) infoSignalInterfacePrivateMethod() {}
 
+// SwitchDayPhaseInfo sent, when the day procedure goes to the next phase.
+//
+// Candidates - players, who can be voted for (nil means anyone).
+// Speaker - the nominee, who defends himself (only in DefenseDayPhase).
+type SwitchDayPhaseInfo struct {
+	DayPhase   DayPhase
+	Candidates []player.IDType
+	Speaker    *player.IDType
+}
+
+func (SwitchDayPhaseInfo) infoSignalInterfacePrivateMethod() {}
+
+// LastWordsInfo sent, when it is the turn of the dying player to say his last words.
+type LastWordsInfo struct {
+	Speaker  player.IDType
+	Deadline time.Duration
+}
+
+func (LastWordsInfo) infoSignalInterfacePrivateMethod() {}
+
+// PlayerAFKInfo sent with NotifyAFKPolicy, when the player is AFK or disconnected (see AFKOpt).
+// Use Game.ReplacePlayer or Game.KickPlayer.
+type PlayerAFKInfo struct {
+	PlayerID       player.IDType
+	MissedPhases   int
+	IsDisconnected bool
+}
+
+func (PlayerAFKInfo) infoSignalInterfacePrivateMethod() {}
+
+// HostActionInfo sent, when the host uses the controls of the game (see host.go).
+//
+// PlayerID and Reason are set only for ForceKillHostAction and ReviveHostAction,
+// Duration - only for ExtendDeadlineHostAction.
+type HostActionInfo struct {
+	Action   HostAction
+	PlayerID player.IDType
+	Reason   player.DeadReason
+	Duration time.Duration
+}
+
+func (HostActionInfo) infoSignalInterfacePrivateMethod() {}
+
+// VoteCastInfo sent on every accepted vote: night (State is NightState) and day.
+// The night votes show the roles, so do not show them to the players as is.
+//
+// Votes contains 2 IDs for the roles with IsTwoVotes, EmptyVoteInt means the empty vote.
+// IsAuto is true, if the vote was stood by the game (see NightVoteData).
+type VoteCastInfo struct {
+	State    State
+	DayPhase DayPhase
+	VoterID  player.IDType
+	Votes    []player.IDType
+	IsAuto   bool
+}
+
+func (VoteCastInfo) infoSignalInterfacePrivateMethod() {}
+
+// VoteChangedInfo sent, when the player changes his vote in the same day voting.
+type VoteChangedInfo struct {
+	DayPhase DayPhase
+	VoterID  player.IDType
+	Previous player.IDType
+	Vote     player.IDType
+}
+
+func (VoteChangedInfo) infoSignalInterfacePrivateMethod() {}
+
+type PlayerDiedInfo struct {
+	PlayerID  player.IDType
+	Reason    player.DeadReason
+	LivedDays int
+}
+
+func (PlayerDiedInfo) infoSignalInterfacePrivateMethod() {}
+
+// PlayerRevivedInfo sent, when the dead player is returned to the game (see Game.Revive).
+type PlayerRevivedInfo struct {
+	PlayerID player.IDType
+}
+
+func (PlayerRevivedInfo) infoSignalInterfacePrivateMethod() {}
+
+// PlayerMutedInfo sent, when the night action mutes the player (Whore, for example).
+type PlayerMutedInfo struct {
+	PlayerID player.IDType
+	By       *roles.Role
+}
+
+func (PlayerMutedInfo) infoSignalInterfacePrivateMethod() {}
+
+// RoleChangedInfo sent after the reincarnation of the player.
+type RoleChangedInfo struct {
+	PlayerID player.IDType
+	FromRole *roles.Role
+	ToRole   *roles.Role
+}
+
+func (RoleChangedInfo) infoSignalInterfacePrivateMethod() {}
+
+// TimerStartedInfo sent, when the timer of the phase starts: the night voting of the role (VotingRole),
+// the day phase (DayPhase), or the last words (State is LastWordsState).
+type TimerStartedInfo struct {
+	State      State
+	VotingRole *roles.Role
+	DayPhase   DayPhase
+	Deadline   time.Duration
+}
+
+func (TimerStartedInfo) infoSignalInterfacePrivateMethod() {}
+
+// TimerExpiredInfo sent, when the voting is ended by the deadline.
+type TimerExpiredInfo struct {
+	State      State
+	VotingRole *roles.Role
+	DayPhase   DayPhase
+}
+
+func (TimerExpiredInfo) infoSignalInterfacePrivateMethod() {}
+
+// NightResultInfo sent, when the night is applied to the players (see AffectNight).
+type NightResultInfo struct {
+	Log NightLog
+}
+
+func (NightResultInfo) infoSignalInterfacePrivateMethod() {}
+
+// DayResultInfo sent, when the day is applied to the players (see AffectDay).
+type DayResultInfo struct {
+	Log DayLog
+}
+
+func (DayResultInfo) infoSignalInterfacePrivateMethod() {}
+
+// DayVoteTallyChangedInfo sent, when the vote of the day voting is cast, changed or withdrawn.
+type DayVoteTallyChangedInfo struct {
+	Tally DayVoteTally
+}
+
+func (DayVoteTallyChangedInfo) infoSignalInterfacePrivateMethod() {}
+
 // InternalCode
 
 // errs
@@ -97,9 +261,86 @@ func sendFatalSignal(ch chan<- ErrSignal, err error) {
 
 // info
 
+// infoQueue keeps the order of the info signals, but does not block the game,
+// while nobody reads the info chan.
+type infoQueue struct {
+	sync.Mutex
+	signals   []InfoSignal
+	isSending bool
+	// How many signals are queued and received over the game.
+	queued, received int
+	// isReceived is closed and replaced on every received signal.
+	isReceived chan struct{}
+}
+
+// sendInfo can be used under the game lock.
+func (g *Game) sendInfo(signal InfoSignal) {
+	q := g.infoQueue
+	q.Lock()
+	defer q.Unlock()
+	q.signals = append(q.signals, signal)
+	q.queued++
+	if !q.isSending {
+		q.isSending = true
+		go g.flushInfo()
+	}
+}
+
+func (g *Game) flushInfo() {
+	q := g.infoQueue
+	for {
+		q.Lock()
+		if len(q.signals) == 0 {
+			q.isSending = false
+			q.Unlock()
+			return
+		}
+		signal := q.signals[0]
+		q.signals = q.signals[1:]
+		q.Unlock()
+
+		g.infoSender <- signal
+
+		q.Lock()
+		q.received++
+		if q.isReceived != nil {
+			close(q.isReceived)
+			q.isReceived = nil
+		}
+		q.Unlock()
+	}
+}
+
+// waitInfo waits, until the signals sent before the call are received.
+// The game calls it before every timer of the phase, so you always know about the phase before its timer.
+func (g *Game) waitInfo() {
+	q := g.infoQueue
+	q.Lock()
+	target := q.queued
+	q.Unlock()
+	for {
+		q.Lock()
+		if q.received >= target {
+			q.Unlock()
+			return
+		}
+		if q.isReceived == nil {
+			q.isReceived = make(chan struct{})
+		}
+		isReceived := q.isReceived
+		q.Unlock()
+
+		select {
+		case <-isReceived:
+		case <-g.ctx.Done():
+			return
+		}
+	}
+}
+
 func (g *Game) newSwitchStateSignal() InfoSignal {
 	return InfoSignal{
-		InitialTime:    time.Now(),
+		InitialTime:    g.clock.Now(),
 		InfoSignalType: SwitchStateSignal,
 		Info: SwitchStateInfo{
 			DayCounter:    g.nightCounter,
@@ -111,7 +352,7 @@ func (g *Game) newSwitchStateSignal() InfoSignal {
 
 func (g *Game) newSwitchVotingRoleSignal() InfoSignal {
 	return InfoSignal{
-		InitialTime:    time.Now(),
+		InitialTime:    g.clock.Now(),
 		InfoSignalType: SwitchVotingRoleSignal,
 		Info: SwitchVotingRoleInfo{
 			CurrVotingRole: g.nightVoting,
@@ -119,9 +360,71 @@ func (g *Game) newSwitchVotingRoleSignal() InfoSignal {
 	}
 }
 
+func (g *Game) newSwitchDayPhaseSignal(speaker *player.IDType) InfoSignal {
+	return InfoSignal{
+		InitialTime:    g.clock.Now(),
+		InfoSignalType: SwitchDayPhaseSignal,
+		Info: SwitchDayPhaseInfo{
+			DayPhase:   g.dayPhase,
+			Candidates: g.dayCandidates,
+			Speaker:    speaker,
+		},
+	}
+}
+
+func (g *Game) newLastWordsSignal(speaker player.IDType, deadline time.Duration) InfoSignal {
+	return InfoSignal{
+		InitialTime:    g.clock.Now(),
+		InfoSignalType: LastWordsSignal,
+		Info: LastWordsInfo{
+			Speaker:  speaker,
+			Deadline: deadline,
+		},
+	}
+}
+
+func (g *Game) newPlayerAFKSignal(info PlayerAFKInfo) InfoSignal {
+	return InfoSignal{
+		InitialTime:    g.clock.Now(),
+		InfoSignalType: PlayerAFKSignal,
+		Info:           info,
+	}
+}
+
+func (g *Game) newInfoSignal(signalType InfoSignalType, info infoSignalInterface) InfoSignal {
+	return InfoSignal{
+		InitialTime:    g.clock.Now(),
+		InfoSignalType: signalType,
+		Info:           info,
+	}
+}
+
+// sendVoteCast can be used under the game lock.
+func (g *Game) sendVoteCast(voterID player.IDType, isAuto bool, votes ...player.IDType) {
+	g.sendInfo(g.newVoteCastSignal(voterID, isAuto, votes...))
+}
+
+func (g *Game) newVoteCastSignal(voterID player.IDType, isAuto bool, votes ...player.IDType) InfoSignal {
+	return g.newInfoSignal(VoteCastSignal, VoteCastInfo{
+		State:    g.state,
+		DayPhase: g.dayPhase,
+		VoterID:  voterID,
+		Votes:    votes,
+		IsAuto:   isAuto,
+	})
+}
+
+func (g *Game) sendPlayerDied(data PlayerDiedData) {
+	g.sendInfo(g.newInfoSignal(PlayerDiedSignal, PlayerDiedInfo{
+		PlayerID:  data.PlayerID,
+		Reason:    data.Reason,
+		LivedDays: data.LivedDays,
+	}))
+}
+
 func (g *Game) newFinishGameSignal() InfoSignal {
 	return InfoSignal{
-		InitialTime:    time.Now(),
+		InitialTime:    g.clock.Now(),
 		InfoSignalType: FinishGameSignal,
 		Info:           FinishGameInfo{},
 	}
@@ -9,6 +9,7 @@ const (
 	StartingState   = "starting"
 	NightState      = "night"
 	DayState        = "day"
+	LastWordsState  = "last words"
 	FinishState     = "finished"
 )
 
@@ -17,7 +18,7 @@ func (g *Game) IsFinished() bool {
 }
 
 func (g *Game) IsRunning() bool {
-	return g.state == NightState || g.state == DayState
+	return g.state == NightState || g.state == DayState || g.state == LastWordsState
 }
 
 // _________________
@@ -40,11 +41,15 @@ func (g *Game) getNextState() State {
 		return DayState
 	case DayState:
 		return NightState
+	case LastWordsState:
+		// The last words are said after the night, or after the day.
+		if g.previousState == NightState {
+			return DayState
+		}
+		return NightState
 	default:
 		panic("unknown game state")
 	}
-
-	return g.previousState
 }
 
 func (g *Game) SetState(state State) {
@@ -53,6 +58,11 @@ func (g *Game) SetState(state State) {
 	currGState := g.state
 	g.previousState = currGState
 	g.state = state
+	g.record(StateSwitchedEvent, StateSwitchedData{
+		NightCounter:  g.nightCounter,
+		PreviousState: g.previousState,
+		NewState:      g.state,
+	})
 }
 
 func (g *Game) SwitchState() {
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/https-whoyan/MafiaCore/player"
+)
+
+// This file contains the live tally of the day voting.
+//
+// SetDayVote and WithdrawDayVote change the votes of the current day voting,
+// every change is sent as DayVoteTallyChangedInfo.
+
+var NotVotedErr = errors.New("player has not voted")
+
+// DayVoteTally is the score of the current day voting.
+type DayVoteTally struct {
+	// NoDayPhase, if there is no day voting now.
+	DayPhase DayPhase
+	// Players, who can be voted for (nil means anyone).
+	Candidates []player.IDType
+	// Key - candidate, value - sorted IDs of his voters.
+	// The empty votes are under EmptyVoteInt.
+	Votes map[player.IDType][]player.IDType
+	// Sorted IDs of the active players, who have not voted yet.
+	NotVoted []player.IDType
+	// Sorted candidates with the most votes, the empty votes are ignored.
+	Leaders []player.IDType
+}
+
+// GetDayVoteTally returns the tally of the current day voting.
+func (g *Game) GetDayVoteTally() DayVoteTally {
+	g.RLock()
+	defer g.RUnlock()
+	return g.newDayVoteTally()
+}
+
+// WithdrawDayVote cancels the vote of the player in the current day voting, as if he has not voted.
+func (g *Game) WithdrawDayVote(voterID string, isServerID bool) error {
+	g.RLock()
+	done := g.dayVotingDone
+	g.RUnlock()
+
+	vP := NewVoteProvider(voterID, EmptyVoteStr, isServerID, false)
+	if err := g.dayVoteValidator(vP); err != nil {
+		return err
+	}
+	voter, _, _ := g.oneVoteHelper(vP)
+
+	g.Lock()
+	defer g.Unlock()
+	if g.dayVotes == nil || g.dayVotingDone != done {
+		return IncorrectVoteTimeErr
+	}
+	if _, ok := g.dayVotes[voter.ID]; !ok {
+		return NotVotedErr
+	}
+	delete(g.dayVotes, voter.ID)
+	voter.DayVote = EmptyVoteInt
+	g.record(DayVoteWithdrawnEvent, DayVoteWithdrawnData{VoterID: voter.ID})
+	g.sendDayVoteTally()
+	return nil
+}
+
+// newDayVoteTally used under the game lock.
+func (g *Game) newDayVoteTally() DayVoteTally {
+	if g.dayVotes == nil {
+		return DayVoteTally{DayPhase: NoDayPhase}
+	}
+	tally := DayVoteTally{
+		DayPhase:   g.dayPhase,
+		Candidates: slices.Clone(g.dayCandidates),
+		Votes:      make(map[player.IDType][]player.IDType),
+		Leaders:    getDayVotesLeaders(g.dayVotes),
+	}
+	// The IDs are sorted, so the voters are too.
+	for _, id := range g.active.GetIDs() {
+		vote, ok := g.dayVotes[id]
+		if !ok {
+			tally.NotVoted = append(tally.NotVoted, id)
+			continue
+		}
+		tally.Votes[vote] = append(tally.Votes[vote], id)
+	}
+	return tally
+}
+
+// sendDayVoteTally used under the game lock.
+func (g *Game) sendDayVoteTally() {
+	g.sendInfo(g.newInfoSignal(DayVoteTallyChangedSignal, DayVoteTallyChangedInfo{
+		Tally: g.newDayVoteTally(),
+	}))
+}
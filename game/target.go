@@ -0,0 +1,150 @@
+package game
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+	rolesPack "github.com/https-whoyan/MafiaCore/roles"
+)
+
+// This file contains the night targeting rules of the roles (see rolesPack.TargetRules),
+// and the legal targets of the voter for the clients (GetLegalTargets).
+
+var (
+	SelfTargetLimitErr = errors.New("self target limit is reached")
+	RepeatTargetErr    = errors.New("cannot target the same player two nights in a row")
+	TeammateTargetErr  = errors.New("cannot target a teammate")
+)
+
+// TargetRulesOpt replaces the targeting rules of the role in the game. For example, the Doctor:
+//
+//	TargetRulesOpt(rolesPack.Doctor, rolesPack.TargetRules{SelfTargetLimit: 1, NoRepeatTarget: true})
+func TargetRulesOpt(role *rolesPack.Role, rules rolesPack.TargetRules) Option {
+	return func(g *Game) { g.targetRules[role] = rules }
+}
+
+// GetTargetRules returns the targeting rules of the role in the game.
+func (g *Game) GetTargetRules(role *rolesPack.Role) rolesPack.TargetRules {
+	if rules, ok := g.targetRules[role]; ok {
+		return rules
+	}
+	return role.TargetRules
+}
+
+// nightTargetValidator checks the not empty targets of the night vote of the voter
+// by the rules of the voting role, so the Leader (see TeamVotePolicy) votes by the rules of the role too.
+//
+// Used under the game lock.
+func (g *Game) nightTargetValidator(voter *playerPack.Player, targets ...*playerPack.Player) error {
+	rules := g.GetTargetRules(g.nightVoting)
+	pastVotes := g.pastNightVotes(voter)
+	var lastNightVotes []playerPack.IDType
+	if n := len(pastVotes); n > 0 {
+		if voter.Role.IsTwoVotes && n > 1 {
+			lastNightVotes = pastVotes[n-2:]
+		} else {
+			lastNightVotes = pastVotes[n-1:]
+		}
+	}
+
+	for _, target := range targets {
+		if target == voter {
+			switch {
+			case rules.NoSelfTarget:
+				return CannotVoteToYourselfErr
+			case rules.SelfTargetLimit > 0:
+				selfTargets := 0
+				for _, vote := range pastVotes {
+					if vote == voter.ID {
+						selfTargets++
+					}
+				}
+				if selfTargets >= rules.SelfTargetLimit {
+					return SelfTargetLimitErr
+				}
+			case !g.voteForYourself:
+				return CannotVoteToYourselfErr
+			}
+		} else if rules.NoTeammateTarget && target.Role.Team == g.nightVoting.Team {
+			return TeammateTargetErr
+		}
+		if rules.NoRepeatTarget && slices.Contains(lastNightVotes, target.ID) {
+			return RepeatTargetErr
+		}
+	}
+	return nil
+}
+
+// pastNightVotes returns the votes of the voter without his votes in the current turn, which can be superseded.
+//
+// Used under the game lock.
+func (g *Game) pastNightVotes(voter *playerPack.Player) []playerPack.IDType {
+	turn := g.nightTurns[voter.Role]
+	if turn == nil || turn.isClosed {
+		return voter.Votes
+	}
+	votes, ok := turn.votes[voter.ID]
+	if !ok || turn.isLeader[voter.ID] {
+		return voter.Votes
+	}
+	return voter.Votes[:len(voter.Votes)-len(votes)]
+}
+
+// ____________
+// Legal targets
+// ____________
+
+// GetLegalTargets returns the sorted IDs of the players, whom the voter can vote for now,
+// at the night (in the turn of his role) or at the day voting.
+// The empty vote is always allowed, so it is not included.
+//
+// The targets are checked by the same validators, as the votes. For the role with 2 votes,
+// the target is legal, if there is a pair with it, which the voter can vote for.
+//
+// Returns error, if the voter can not vote now.
+func (g *Game) GetLegalTargets(voterID string, isServerID bool) ([]playerPack.IDType, error) {
+	if err := g.basicVoteValidator(NewVoteProvider(voterID, EmptyVoteStr, isServerID, false)); err != nil {
+		return nil, err
+	}
+	g.RLock()
+	voter := g.active.SearchPlayerByID(voterID, isServerID)
+	state, phase := g.state, g.dayPhase
+	isNightTurn := g.nightVoting != nil && (g.nightVoting == voter.Role || g.isNightTurnLeader(voter))
+	ids := g.active.GetIDs()
+	g.RUnlock()
+
+	var validate func(target playerPack.IDType) error
+	switch {
+	case state == NightState && isNightTurn && voter.Role.IsTwoVotes:
+		validate = func(target playerPack.IDType) (err error) {
+			for _, pair := range ids {
+				err = g.nightTwoVoteProviderValidator(NewTwoVoteProvider(
+					voterID, strconv.Itoa(int(target)), strconv.Itoa(int(pair)), isServerID, false))
+				if err == nil {
+					return nil
+				}
+			}
+			return err
+		}
+	case state == NightState && isNightTurn:
+		validate = func(target playerPack.IDType) error {
+			return g.nightVoteValidator(NewVoteProvider(voterID, strconv.Itoa(int(target)), isServerID, false))
+		}
+	case state == DayState && phase.IsVoting():
+		validate = func(target playerPack.IDType) error {
+			return g.dayVoteValidator(NewVoteProvider(voterID, strconv.Itoa(int(target)), isServerID, false))
+		}
+	default:
+		return nil, IncorrectVoteTimeErr
+	}
+
+	targets := make([]playerPack.IDType, 0, len(ids))
+	for _, id := range ids {
+		if validate(id) == nil {
+			targets = append(targets, id)
+		}
+	}
+	return targets, nil
+}
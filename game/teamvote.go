@@ -0,0 +1,273 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+
+	playerPack "github.com/https-whoyan/MafiaCore/player"
+	rolesPack "github.com/https-whoyan/MafiaCore/roles"
+)
+
+// This file contains how the role with several players (3 Mafia, for example) decides its night votes.
+//
+// Every player of the role votes by himself in the turn of the role (see nightTurn),
+// his votes are kept as is, and TeamVotePolicy of the role turns them into the decision of the role.
+// The decision is applied once, and saved in NightLog Decisions.
+
+// TeamVoteKind presents how the votes of the players of the role become the decision.
+type TeamVoteKind uint8
+
+const (
+	// FirstTeamVote the first not empty vote is the decision. The first vote completes the turn.
+	FirstTeamVote TeamVoteKind = iota
+	// MajorityTeamVote the most voted target is the decision.
+	// The tie is broken by the vote of the Leader, or by the first vote.
+	//
+	// The turn is complete, when everyone has voted, or the target has more than half of the votes.
+	MajorityTeamVote
+	// UnanimousTeamVote the decision is made, only if all not muted players of the role vote the same.
+	// The turn is complete, when everyone has voted the same.
+	UnanimousTeamVote
+	// LastTeamVote the last not empty vote before the deadline is the decision.
+	// The turn is always complete by the deadline.
+	LastTeamVote
+)
+
+// TeamVotePolicy presents how the role decides its night votes.
+//
+// Default value: FirstTeamVote.
+//
+// Adjustable by TeamVotePolicyOpt.
+type TeamVotePolicy struct {
+	Kind TeamVoteKind
+	// Leader is the role, whose players vote in the turn of the role too, and break the tie (Don for Mafia).
+	// Used only by MajorityTeamVote. The Leader must vote the same way (IsTwoVotes) as the role.
+	Leader *rolesPack.Role
+}
+
+// TeamVotePolicyOpt sets how the role decides its night votes. For example, the Mafia with the Don:
+//
+//	TeamVotePolicyOpt(rolesPack.Mafia, TeamVotePolicy{Kind: MajorityTeamVote, Leader: rolesPack.Don})
+func TeamVotePolicyOpt(role *rolesPack.Role, policy TeamVotePolicy) Option {
+	return func(g *Game) { g.teamVotePolicies[role] = policy }
+}
+
+// GetTeamVotePolicy returns the policy of the role (see TeamVotePolicyOpt).
+func (g *Game) GetTeamVotePolicy(role *rolesPack.Role) TeamVotePolicy {
+	return g.teamVotePolicies[role]
+}
+
+// ____________
+// Leader
+// ____________
+
+// isNightTurnLeader reports whether the voter votes in the current turn as its Leader.
+func (g *Game) isNightTurnLeader(voter *playerPack.Player) bool {
+	if g.nightVoting == nil || voter.Role == g.nightVoting {
+		return false
+	}
+	policy := g.teamVotePolicies[g.nightVoting]
+	return policy.Kind == MajorityTeamVote && policy.Leader == voter.Role
+}
+
+// ____________
+// Turn
+// ____________
+
+// nightTurnVoters returns the count of the players, who can vote in the turn of the role.
+// Used under the game lock.
+func (g *Game) nightTurnVoters(role *rolesPack.Role) int {
+	voters := 0
+	for _, p := range *g.active {
+		isVoter := p.Role == role || g.isNightTurnLeader(p)
+		if isVoter && p.InteractionStatus != playerPack.Muted {
+			voters++
+		}
+	}
+	return voters
+}
+
+// isNightTurnComplete reports whether the role does not need to wait for the deadline, see TeamVoteKind.
+func (g *Game) isNightTurnComplete(role *rolesPack.Role, turn *nightTurn) bool {
+	g.RLock()
+	defer g.RUnlock()
+	voters := g.nightTurnVoters(role)
+	switch g.teamVotePolicies[role].Kind {
+	case MajorityTeamVote:
+		if len(turn.votes) >= voters {
+			return true
+		}
+		for _, count := range turn.countVotes() {
+			if 2*count > voters {
+				return true
+			}
+		}
+		return false
+	case UnanimousTeamVote:
+		return len(turn.votes) >= voters && len(turn.countVotes()) <= 1 && !turn.hasEmptyAndNot()
+	case LastTeamVote:
+		return false
+	default:
+		return len(turn.votes) > 0
+	}
+}
+
+// decide returns the decision of the closed turn by the policy, or nil, if nothing is decided.
+func (t *nightTurn) decide(policy TeamVotePolicy, voters int) []playerPack.IDType {
+	switch policy.Kind {
+	case MajorityTeamVote:
+		counts := t.countVotes()
+		var leaders []string
+		mxCount := 0
+		for _, id := range t.order {
+			key := votesKey(t.votes[id])
+			count, ok := counts[key]
+			switch {
+			case !ok || slices.Contains(leaders, key):
+			case count > mxCount:
+				mxCount = count
+				leaders = []string{key}
+			case count == mxCount:
+				leaders = append(leaders, key)
+			}
+		}
+		for _, id := range t.order {
+			if t.isLeader[id] && slices.Contains(leaders, votesKey(t.votes[id])) {
+				return t.votes[id]
+			}
+		}
+		for _, id := range t.order {
+			if len(leaders) > 0 && votesKey(t.votes[id]) == leaders[0] {
+				return t.votes[id]
+			}
+		}
+	case UnanimousTeamVote:
+		if len(t.votes) >= voters && len(t.countVotes()) == 1 && !t.hasEmptyAndNot() {
+			return t.votes[t.order[0]]
+		}
+	case LastTeamVote:
+		for i := len(t.order) - 1; i >= 0; i-- {
+			if votes := t.votes[t.order[i]]; !isEmptyVotes(votes) {
+				return votes
+			}
+		}
+	default:
+		for _, id := range t.order {
+			if votes := t.votes[id]; !isEmptyVotes(votes) {
+				return votes
+			}
+		}
+	}
+	return nil
+}
+
+// countVotes Key - the not empty votes (see votesKey), value - how many players have voted so.
+func (t *nightTurn) countVotes() map[string]int {
+	counts := make(map[string]int)
+	for _, votes := range t.votes {
+		if !isEmptyVotes(votes) {
+			counts[votesKey(votes)]++
+		}
+	}
+	return counts
+}
+
+// hasEmptyAndNot reports whether some players have voted empty, and some have not.
+func (t *nightTurn) hasEmptyAndNot() bool {
+	var hasEmpty, hasNotEmpty bool
+	for _, votes := range t.votes {
+		if isEmptyVotes(votes) {
+			hasEmpty = true
+		} else {
+			hasNotEmpty = true
+		}
+	}
+	return hasEmpty && hasNotEmpty
+}
+
+func votesKey(votes []playerPack.IDType) string {
+	return fmt.Sprint(votes)
+}
+
+func isEmptyVotes(votes []playerPack.IDType) bool {
+	for _, vote := range votes {
+		if vote != EmptyVoteInt {
+			return false
+		}
+	}
+	return true
+}
+
+// ____________
+// Decision
+// ____________
+
+// resolveNightTurn makes the decision of the closed turn of the role.
+// The players with the role, who have not voted, get the empty votes.
+//
+// Returns the player, whose last votes are the decision, to apply it,
+// or nil, if the role has decided nothing.
+func (g *Game) resolveNightTurn(role *rolesPack.Role) *playerPack.Player {
+	g.Lock()
+	defer g.Unlock()
+	turn := g.nightTurns[role]
+	players := g.active.SearchAllPlayersWithRole(role)
+	ids := players.GetIDs()
+
+	emptyVotes := []playerPack.IDType{EmptyVoteInt}
+	if role.IsTwoVotes {
+		emptyVotes = append(emptyVotes, EmptyVoteInt)
+	}
+	for _, id := range ids {
+		if _, ok := turn.votes[id]; !ok {
+			g.standNightVotes((*players)[id], emptyVotes)
+		}
+	}
+
+	turn.decision = turn.decide(g.teamVotePolicies[role], g.nightTurnVoters(role))
+	if turn.decision == nil || len(ids) == 0 {
+		return nil
+	}
+	for _, id := range turn.order {
+		if p := (*players)[id]; p != nil && slices.Equal(turn.votes[id], turn.decision) {
+			turn.executor = p
+			return p
+		}
+	}
+	// Only the Leader has voted so, the decision replaces the votes of the player of the role in this night,
+	// preferably the empty ones.
+	turn.executor = (*players)[ids[0]]
+	for _, id := range ids {
+		if isEmptyVotes(turn.votes[id]) {
+			turn.executor = (*players)[id]
+			break
+		}
+	}
+	g.replaceNightVotes(turn.executor, turn.decision)
+	return turn.executor
+}
+
+// standNightVotes stands the votes of the voter, who has not voted in this night.
+// Used under the game lock.
+func (g *Game) standNightVotes(voter *playerPack.Player, votes []playerPack.IDType) {
+	voter.Votes = append(voter.Votes, votes...)
+	g.record(NightVoteAcceptedEvent, NightVoteData{
+		VoterID: voter.ID,
+		Votes:   votes,
+		IsAuto:  true,
+	})
+	g.sendVoteCast(voter.ID, true, votes...)
+}
+
+// replaceNightVotes replaces the votes of the voter in this night, so he still has one vote (or 2) per night.
+// Used under the game lock.
+func (g *Game) replaceNightVotes(voter *playerPack.Player, votes []playerPack.IDType) {
+	voter.Votes = append(voter.Votes[:len(voter.Votes)-len(votes)], votes...)
+	g.record(NightVoteAcceptedEvent, NightVoteData{
+		VoterID:      voter.ID,
+		Votes:        votes,
+		IsAuto:       true,
+		IsSuperseded: true,
+	})
+	g.sendVoteCast(voter.ID, true, votes...)
+}
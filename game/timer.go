@@ -9,10 +9,10 @@ import (
 
 // Used to simulate.
 
-func getRandomDuration() time.Duration {
-	minMilliSecond := myTime.FakeVotingMinSeconds * 1000
-	maxMilliSecond := myTime.FakeVotingMaxSeconds * 1000
-	randMilliSecondDuration := rand.Intn(maxMilliSecond-minMilliSecond+1) + minMilliSecond
+func getRandomDuration(rnd *rand.Rand, timings myTime.Timings) time.Duration {
+	minMilliSecond := timings.FakeVotingMin.Milliseconds()
+	maxMilliSecond := timings.FakeVotingMax.Milliseconds()
+	randMilliSecondDuration := rnd.Int63n(maxMilliSecond-minMilliSecond+1) + minMilliSecond
 	return time.Duration(randMilliSecondDuration) * time.Millisecond
 }
 
@@ -38,22 +38,73 @@ func compareMaps[K comparable, E comparable](map1, map2 map[K]E) bool {
 	return true
 }
 
-func (g *Game) timer(duration time.Duration) {
-	go func() {
-		ticker := time.NewTicker(duration)
-		defer ticker.Stop()
+func (g *Game) timer(duration time.Duration) myTime.Timer {
+	return g.clock.NewTimer(duration)
+}
 
-		select {
-		case <-ticker.C:
-			g.timerDone <- struct{}{}
-			return
-		case <-g.timerStop:
-			return
-		}
-	}()
+// ____________
+// Phase timer
+// ____________
+
+// phaseTimer is the timer of the current phase (the night voting of the role, the day voting,
+// the defense or the last words), which the host can pause, extend or skip (see host.go).
+type phaseTimer struct {
+	myTime.Timer
+	// deadline of the running timer.
+	deadline time.Time
+	// remaining of the paused timer.
+	remaining time.Duration
+	isPaused  bool
+	// isSkipped the timer was fired by SkipCurrentVoting, not by the deadline.
+	isSkipped bool
+}
+
+// newPhaseTimer starts the timer of the phase. If the game is paused, the timer waits for Resume.
+func (g *Game) newPhaseTimer(duration time.Duration) *phaseTimer {
+	return g.startPhaseTimer(duration, duration)
+}
+
+// newFakePhaseTimer is the timer of the night voting of the role, whose players are all muted.
+// It goes for the random time, but the deadline is announced as usual.
+func (g *Game) newFakePhaseTimer(deadline time.Duration) *phaseTimer {
+	return g.startPhaseTimer(getRandomDuration(g.rand, g.timings), deadline)
+}
+
+func (g *Game) startPhaseTimer(duration, deadline time.Duration) *phaseTimer {
+	g.waitInfo()
+	g.Lock()
+	defer g.Unlock()
+	g.sendInfo(g.newInfoSignal(TimerStartedSignal, TimerStartedInfo{
+		State:      g.state,
+		VotingRole: g.nightVoting,
+		DayPhase:   g.dayPhase,
+		Deadline:   deadline,
+	}))
+	t := &phaseTimer{
+		Timer:    g.timer(duration),
+		deadline: g.clock.Now().Add(duration),
+	}
+	if g.isPaused {
+		t.Stop()
+		t.isPaused = true
+		t.remaining = duration
+	}
+	g.currentTimer = t
+	return t
+}
+
+func (g *Game) stopPhaseTimer(t *phaseTimer) {
+	g.Lock()
+	defer g.Unlock()
+	t.Stop()
+	if g.currentTimer == t {
+		g.currentTimer = nil
+	}
 }
 
-func (g *Game) randomTimer() {
-	duration := getRandomDuration()
-	g.timer(duration)
+// isSkipped reports whether the fired timer was skipped by the host.
+func (g *Game) isSkipped(t *phaseTimer) bool {
+	g.RLock()
+	defer g.RUnlock()
+	return t.isSkipped
 }
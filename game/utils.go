@@ -1,7 +1,10 @@
 package game
 
 import (
+	"sort"
+
 	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/registry"
 	"github.com/https-whoyan/MafiaCore/roles"
 	"github.com/samber/lo"
 )
@@ -15,6 +18,7 @@ func (g *Game) ClearDayVotes() {
 	for _, activePlayer := range *allPlayers {
 		activePlayer.DayVote = EmptyVoteInt
 	}
+	g.record(DayVotesClearedEvent, DayVotesClearedData{})
 }
 
 func (g *Game) ResetAllInteractionsStatuses() {
@@ -25,60 +29,79 @@ func (g *Game) ResetAllInteractionsStatuses() {
 	for _, activePlayer := range *allPlayers {
 		activePlayer.InteractionStatus = player.Passed
 	}
+	g.record(InteractionsResetEvent, InteractionsResetData{})
 }
 
 // UnderstandWinnerTeam is used to define scenarios where one team has 100% already won.
 // A prime example is the 3/3 vote.
+//
+// See registry.GetTeamWinCondition and TeamWinConditionOpt.
 func (g *Game) UnderstandWinnerTeam() *roles.Team {
 	g.RLock()
 	defer g.RUnlock()
-	allPlayers := g.active
-
-	// int represent count of players by their team
-	teamsMp := lo.CountValues(lo.Map(
-		lo.Values(*allPlayers),
-		func(p *player.Player, _ int) roles.Team {
-			return p.Role.Team
-		}),
-	)
 
-	switch len(teamsMp) {
-	case 1:
-		var winnerTeam roles.Team
-		for team := range teamsMp {
-			winnerTeam = team
-			break
+	for _, team := range g.getTeamsWinOrder() {
+		condition, isHouseRule := g.teamWinConditions[team]
+		if !isHouseRule {
+			condition = registry.GetTeamWinCondition(team)
 		}
-		return &winnerTeam
-	case 2:
-		// In this case, it all depends on the number of peaceful people.
-		peacefulCnt, isNonZero := teamsMp[roles.PeacefulTeam]
-		if !isNonZero { // (Is zero)
-			// So that leaves the mafia team and the maniac team.
-			// But the mafia team knows each other. So the mafia wins.
-			winnerTeam := roles.MafiaTeam
-			return &winnerTeam
+		if condition == nil {
+			continue
 		}
-		// We find out the number of people from the opposite team.
-		var (
-			anotherTeam    roles.Team
-			anotherTeamCnt int
-		)
-		for team, playersCnt := range teamsMp {
-			if team != roles.PeacefulTeam {
-				anotherTeamCnt = playersCnt
-				anotherTeam = team
-				break
-			}
+		if condition.IsWon(g.env()) {
+			winnerTeam := team
+			return &winnerTeam
 		}
-		// Compared to the number of civilians
-		if anotherTeamCnt >= peacefulCnt {
-			return &anotherTeam
+	}
+	return nil
+}
+
+// getTeamsWinOrder returns the teams in order of checking their win conditions:
+// teams with house rules first, then all other teams of the config.
+//
+// Used under the game lock.
+func (g *Game) getTeamsWinOrder() []roles.Team {
+	order := make([]roles.Team, 0, len(g.teamWinOrder))
+	order = append(order, g.teamWinOrder...)
+	for _, team := range g.rolesConfig.GetTeamsByCfg() {
+		if !lo.Contains(order, team) {
+			order = append(order, team)
 		}
-		// return nil
-		return nil
 	}
+	return order
+}
 
-	// 3 or more
+// UnderstandSoloWinner is used to define the role that won the game on its own,
+// regardless of its team (Fool, for example).
+//
+// See registry.RoleBehavior WinCondition and SoloWinConditionOpt.
+func (g *Game) UnderstandSoloWinner() *roles.Role {
+	g.RLock()
+	defer g.RUnlock()
+
+	// For determinism.
+	configRoles := make([]*roles.Role, 0, len(g.rolesConfig.RolesMp))
+	for _, roleCfg := range g.rolesConfig.RolesMp {
+		configRoles = append(configRoles, roleCfg.Role)
+	}
+	sort.Slice(configRoles, func(i, j int) bool {
+		return configRoles[i].Name < configRoles[j].Name
+	})
+	for _, role := range configRoles {
+		if condition := g.getSoloWinCondition(role); condition != nil && condition.IsWon(g.env()) {
+			return role
+		}
+	}
 	return nil
 }
+
+// getSoloWinCondition returns the condition under which the role wins on its own in this game:
+// from SoloWinConditionOpt, otherwise the registered one. nil means that the role plays only for its team.
+//
+// Used under the game lock.
+func (g *Game) getSoloWinCondition(role *roles.Role) registry.WinCondition {
+	if condition, ok := g.soloWinConditions[role]; ok {
+		return condition
+	}
+	return registry.GetBehavior(role).WinCondition()
+}
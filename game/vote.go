@@ -3,6 +3,7 @@ package game
 import (
 	"errors"
 	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/samber/lo"
 )
 
 // This file contains everything about the voting mechanics.
@@ -121,9 +122,12 @@ var (
 	IncorrectVoteTimeErr       = errors.New("incorrect Vote time")
 	ToVotedPlayerIsNotAliveErr = errors.New("toVoted player is not alive")
 	CannotVoteToYourselfErr    = errors.New("cannot vote to yourself")
+	NotCandidateErr            = errors.New("toVoted player is not a candidate")
 
 	TwoVotesOneOfEmptyErr   = errors.New("both votes must be either blank or not blank")
 	TwoVotesSimilarVotesErr = errors.New("votes are similar")
+
+	VoteTooLateErr = errors.New("voting is over")
 )
 
 // Helpers
@@ -227,17 +231,24 @@ func (g *Game) nightVoteValidator(vP NightVoteProviderInterface) error {
 	if isEmpty {
 		return nil
 	}
-	if !g.voteForYourself && voter == toVoter {
-		return CannotVoteToYourselfErr
-	}
-	if g.nightVoting != voter.Role {
+	g.RLock()
+	defer g.RUnlock()
+	isLeader := g.isNightTurnLeader(voter)
+	if g.nightVoting != voter.Role && !isLeader {
 		return IncorrectVoteTimeErr
 	}
+	if err = g.nightTargetValidator(voter, toVoter); err != nil {
+		return err
+	}
+	if isLeader {
+		return nil
+	}
 	// Check vote ping
+	previousVotes := g.pastNightVotes(voter)
 	previousVotesMp := make(map[player.IDType]bool)
-	startIndex := max(0, len(voter.Votes)-g.votePing)
-	for i := startIndex; i <= len(voter.Votes)-1; i++ {
-		previousVotesMp[voter.Votes[i]] = true
+	startIndex := max(0, len(previousVotes)-g.votePing)
+	for i := startIndex; i <= len(previousVotes)-1; i++ {
+		previousVotesMp[previousVotes[i]] = true
 	}
 	// ValidatedBefore
 	if previousVotesMp[toVoter.ID] {
@@ -266,7 +277,7 @@ func (g *Game) nightTwoVoteProviderValidator(vP NightTwoVoteProviderInterface) e
 	votedPlayer := g.active.SearchPlayerByID(votedPlayerID, isServerIDByPlayer)
 	toVotePlayer1 := g.active.SearchPlayerByID(vote1, isServerIDByVote)
 	toVotePlayer2 := g.active.SearchPlayerByID(vote2, isServerIDByVote)
-	if g.nightVoting != votedPlayer.Role {
+	if g.nightVoting != votedPlayer.Role && !g.isNightTurnLeader(votedPlayer) {
 		return IncorrectVoteTimeErr
 	}
 	if vote1 == EmptyVoteStr && vote2 == EmptyVoteStr {
@@ -275,10 +286,7 @@ func (g *Game) nightTwoVoteProviderValidator(vP NightTwoVoteProviderInterface) e
 	if toVotePlayer1 == nil || toVotePlayer2 == nil {
 		return VotePlayerNotFoundErr
 	}
-	if !g.voteForYourself && (votedPlayer == toVotePlayer1 || votedPlayer == toVotePlayer2) {
-		return CannotVoteToYourselfErr
-	}
-	return nil
+	return g.nightTargetValidator(votedPlayer, toVotePlayer1, toVotePlayer2)
 }
 
 func (g *Game) dayVoteValidator(vP DayVoteProviderInterface) error {
@@ -286,7 +294,10 @@ func (g *Game) dayVoteValidator(vP DayVoteProviderInterface) error {
 	if err != nil {
 		return err
 	}
-	if g.state != DayState {
+	g.RLock()
+	state, phase, candidates := g.state, g.dayPhase, g.dayCandidates
+	g.RUnlock()
+	if state != DayState || !phase.IsVoting() {
 		return IncorrectVoteTimeErr
 	}
 	voter, toVoter, isEmpty := g.oneVoteHelper(vP)
@@ -296,6 +307,9 @@ func (g *Game) dayVoteValidator(vP DayVoteProviderInterface) error {
 	if !g.voteForYourself && voter == toVoter {
 		return CannotVoteToYourselfErr
 	}
+	if candidates != nil && !lo.Contains(candidates, toVoter.ID) {
+		return NotCandidateErr
+	}
 	return nil
 }
 
@@ -303,87 +317,229 @@ func (g *Game) dayVoteValidator(vP DayVoteProviderInterface) error {
 // Vote Functions
 // _______________________________
 
+// _______________________________
+// Vote Results
+// _______________________________
+
+// VoteStatus presents what the game has done with the submitted vote.
+type VoteStatus uint8
+
+const (
+	// VoteAccepted the vote is counted.
+	VoteAccepted VoteStatus = iota
+	// VoteSuperseded the vote is counted instead of the previous vote of the player in the same voting.
+	VoteSuperseded
+	// VoteTooLate the voting was over, when the vote came. Err is VoteTooLateErr.
+	VoteTooLate
+	// VoteRejected the vote is invalid, Err is the reason.
+	VoteRejected
+)
+
+// VoteResult is returned by SubmitNightVote, SubmitNightTwoVote and SubmitDayVote.
+type VoteResult struct {
+	Status VoteStatus
+	// nil, if the vote is counted.
+	Err error
+}
+
+// IsCounted reports whether the vote is counted.
+func (r VoteResult) IsCounted() bool {
+	return r.Status == VoteAccepted || r.Status == VoteSuperseded
+}
+
+func acceptedVote(isSuperseded bool) VoteResult {
+	if isSuperseded {
+		return VoteResult{Status: VoteSuperseded}
+	}
+	return VoteResult{Status: VoteAccepted}
+}
+
+func rejectedVote(err error) VoteResult {
+	if errors.Is(err, VoteTooLateErr) {
+		return VoteResult{Status: VoteTooLate, Err: err}
+	}
+	return VoteResult{Status: VoteRejected, Err: err}
+}
+
 // nightOneVote used after validation, and stand votes
-func (g *Game) nightOneVote(vP NightVoteProviderInterface) {
+func (g *Game) nightOneVote(vP NightVoteProviderInterface) VoteResult {
 	voter, toVote, isEmpty := g.oneVoteHelper(vP)
+	vote := player.IDType(EmptyVoteInt)
+	if !isEmpty {
+		vote = toVote.ID
+	}
 	g.Lock()
 	defer g.Unlock()
-	if isEmpty {
-		voter.Votes = append(voter.Votes, EmptyVoteInt)
-		return
-	}
-	voter.Votes = append(voter.Votes, toVote.ID)
+	return g.nightVote(voter, vote)
 }
 
 // nightTwoVote used after validation, and stand votes
-func (g *Game) nightTwoVote(vP NightTwoVoteProviderInterface) {
+func (g *Game) nightTwoVote(vP NightTwoVoteProviderInterface) VoteResult {
 	voterID, isServerVoterID := vP.GetVotedPlayerID()
 	vote1, vote2, isServerVoteID := vP.GetVotes()
 
-	g.RLock()
+	g.Lock()
+	defer g.Unlock()
 	voter := g.active.SearchPlayerByID(voterID, isServerVoterID)
-	g.RUnlock()
 	if vote1 == EmptyVoteStr && vote2 == EmptyVoteStr {
-		g.Lock()
-		defer g.Unlock()
-		voter.Votes = append(voter.Votes, EmptyVoteInt, EmptyVoteInt)
-		return
+		return g.nightVote(voter, EmptyVoteInt, EmptyVoteInt)
 	}
-	g.RLock()
 	voter1ID := g.active.SearchPlayerByID(vote1, isServerVoteID).ID
 	voter2ID := g.active.SearchPlayerByID(vote2, isServerVoteID).ID
-	g.Lock()
-	defer g.Unlock()
-	voter.Votes = append(voter.Votes, voter1ID, voter2ID)
+	return g.nightVote(voter, voter1ID, voter2ID)
+}
+
+// nightVote puts the votes in the turn of the role of the voter (see nightTurn).
+// If the voter has voted in the turn, his votes are superseded.
+//
+// Used under the game lock.
+//
+// The votes of the Leader (see TeamVotePolicy) are kept only in the turn, not in his Votes.
+func (g *Game) nightVote(voter *player.Player, votes ...player.IDType) VoteResult {
+	turn, isLeader := g.nightTurns[voter.Role], g.isNightTurnLeader(voter)
+	if isLeader {
+		turn = g.nightTurns[g.nightVoting]
+	}
+	if turn == nil || turn.isClosed {
+		return rejectedVote(VoteTooLateErr)
+	}
+	isSuperseded := turn.setVotes(voter.ID, votes)
+	if !isLeader {
+		if isSuperseded {
+			voter.Votes = voter.Votes[:len(voter.Votes)-len(votes)]
+		}
+		voter.Votes = append(voter.Votes, votes...)
+	}
+	turn.isLeader[voter.ID] = isLeader
+	g.markVoted(voter.ID)
+	g.record(NightVoteAcceptedEvent, NightVoteData{
+		VoterID:      voter.ID,
+		Votes:        votes,
+		IsSuperseded: isSuperseded,
+		IsLeader:     isLeader,
+	})
+	g.sendVoteCast(voter.ID, false, votes...)
+	turn.notify()
+	return acceptedVote(isSuperseded)
 }
 
-// dayVote used after validation, and stand votes
-func (g *Game) dayVote(vP NightVoteProviderInterface) {
+// dayVote used after validation, and stand votes.
+// The vote is counted only by the day voting, that was open at the validation (done).
+func (g *Game) dayVote(vP DayVoteProviderInterface, done chan struct{}) VoteResult {
 	voter, toVote, isEmpty := g.oneVoteHelper(vP)
 	g.Lock()
 	defer g.Unlock()
-	if isEmpty {
-		voter.DayVote = EmptyVoteInt
-		return
-	}
-	voter.DayVote = toVote.ID
+	if g.dayVotes == nil || g.dayVotingDone != done {
+		return rejectedVote(VoteTooLateErr)
+	}
+	vote := player.IDType(EmptyVoteInt)
+	if !isEmpty {
+		vote = toVote.ID
+	}
+	previous, isChanged := g.dayVotes[voter.ID]
+	g.dayVotes[voter.ID] = vote
+	voter.DayVote = vote
+	g.markVoted(voter.ID)
+	g.record(DayVoteAcceptedEvent, DayVoteData{
+		VoterID: voter.ID,
+		Vote:    vote,
+	})
+	switch {
+	case !isChanged:
+		g.sendVoteCast(voter.ID, false, vote)
+	case previous != vote:
+		g.sendInfo(g.newInfoSignal(VoteChangedSignal, VoteChangedInfo{
+			DayPhase: g.dayPhase,
+			VoterID:  voter.ID,
+			Previous: previous,
+			Vote:     vote,
+		}))
+	default:
+		return acceptedVote(isChanged)
+	}
+	g.sendDayVoteTally()
+	// The day voting checks, whether it is over.
+	select {
+	case g.dayVoteChan <- struct{}{}:
+	default:
+	}
+	return acceptedVote(isChanged)
 }
 
 // __________________________________________
 
 // Functions of game to set voting.
 // You can use only this functions to interact for vote system
+//
+// The submission of the vote never waits for the game: the voting itself decides, when it is over.
+// Set functions are the same as Submit functions, but return only the error (nil, if the vote is counted).
 
-// SetNightVote Checks the voice for errors, and if it's ok, puts it in right away.
-func (g *Game) SetNightVote(nightVote NightVoteProviderInterface) error {
-	var err error
-	err = g.nightVoteValidator(nightVote)
-	if err != nil {
-		return err
+// SubmitNightVote Checks the voice for errors, and if it's ok, puts it in the turn of the role.
+func (g *Game) SubmitNightVote(nightVote NightVoteProviderInterface) VoteResult {
+	if err := g.nightVoteValidator(nightVote); err != nil {
+		err = g.lateNightVoteErr(err, nightVote)
+		g.recordRejectedOneVote(err, nightVote)
+		return rejectedVote(err)
 	}
-	g.nightOneVote(nightVote)
-	g.voteAccepted <- struct{}{}
-	return nil
+	result := g.nightOneVote(nightVote)
+	if !result.IsCounted() {
+		g.recordRejectedOneVote(result.Err, nightVote)
+	}
+	return result
+}
+
+func (g *Game) SetNightVote(nightVote NightVoteProviderInterface) error {
+	return g.SubmitNightVote(nightVote).Err
+}
+
+// SubmitNightTwoVote Checks the voice for errors, and if it's ok, puts it in the turn of the role.
+func (g *Game) SubmitNightTwoVote(nightVote NightTwoVoteProviderInterface) VoteResult {
+	recordRejected := func(err error) {
+		if nightVote != nil {
+			voterID, isServerID := nightVote.GetVotedPlayerID()
+			vote1, vote2, _ := nightVote.GetVotes()
+			g.recordVoteRejected(err, voterID, isServerID, vote1, vote2)
+		}
+	}
+	if err := g.nightTwoVoteProviderValidator(nightVote); err != nil {
+		err = g.lateNightVoteErr(err, nightVote)
+		recordRejected(err)
+		return rejectedVote(err)
+	}
+	result := g.nightTwoVote(nightVote)
+	if !result.IsCounted() {
+		recordRejected(result.Err)
+	}
+	return result
 }
 
-// SetNightTwoVote Checks the voice for errors, and if it's ok, puts it in right away.
 func (g *Game) SetNightTwoVote(nightVote NightTwoVoteProviderInterface) error {
-	var err error
-	err = g.nightTwoVoteProviderValidator(nightVote)
-	if err != nil {
-		return err
+	return g.SubmitNightTwoVote(nightVote).Err
+}
+
+// SubmitDayVote Checks the voice for errors, and if it's ok, puts it in the current day voting.
+//
+// The player can change his vote by the next SubmitDayVote, or withdraw it by WithdrawDayVote.
+func (g *Game) SubmitDayVote(dayVote DayVoteProviderInterface) VoteResult {
+	// The vote is counted only by the voting, that was open at the call.
+	g.RLock()
+	done, isOpen := g.dayVotingDone, g.dayVotes != nil
+	g.RUnlock()
+
+	if err := g.dayVoteValidator(dayVote); err != nil {
+		if isOpen && errors.Is(err, IncorrectVoteTimeErr) {
+			err = VoteTooLateErr
+		}
+		g.recordRejectedOneVote(err, dayVote)
+		return rejectedVote(err)
 	}
-	g.nightTwoVote(nightVote)
-	g.voteAccepted <- struct{}{}
-	return nil
+	result := g.dayVote(dayVote, done)
+	if !result.IsCounted() {
+		g.recordRejectedOneVote(result.Err, dayVote)
+	}
+	return result
 }
 
 func (g *Game) SetDayVote(dayVote DayVoteProviderInterface) error {
-	var err error
-	err = g.dayVoteValidator(dayVote)
-	if err != nil {
-		return err
-	}
-	g.dayVote(dayVote)
-	return nil
+	return g.SubmitDayVote(dayVote).Err
 }
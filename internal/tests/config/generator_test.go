@@ -0,0 +1,103 @@
+package config
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/registry"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkGeneratedConfig(t *testing.T, cfg *config.RolesConfig, playersCount int) {
+	t.Helper()
+	total := 0
+	for roleName, roleCfg := range cfg.RolesMp {
+		assert.Equal(t, roleName, roleCfg.Role.Name)
+		assert.Positive(t, roleCfg.Count)
+		total += roleCfg.Count
+	}
+	assert.Equal(t, playersCount, cfg.PlayersCount)
+	assert.Equal(t, playersCount, total)
+	assert.GreaterOrEqual(t, len(cfg.GetTeamsByCfg()), 2)
+	assert.LessOrEqual(t, cfg.GetPlayersCountByTeam(roles.MafiaTeam), max(1, (playersCount-1)/3))
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	t.Parallel()
+	for _, chaos := range []float64{0, 0.5, 1} {
+		g := config.NewGenerator(config.GeneratorChaosLevelOpt(chaos))
+		for playersCount := 3; playersCount <= 30; playersCount++ {
+			cfg, err := g.Generate(playersCount)
+			require.NoError(t, err)
+			checkGeneratedConfig(t, cfg, playersCount)
+			if chaos == 0 {
+				assert.False(t, cfg.HasRole(roles.Maniac))
+			}
+		}
+	}
+
+	// Chaos brings the chaos roles.
+	cfg, err := config.NewGenerator(config.GeneratorChaosLevelOpt(1)).Generate(14)
+	require.NoError(t, err)
+	assert.True(t, cfg.HasRole(roles.Maniac))
+	assert.True(t, cfg.HasRole(roles.Fool))
+
+	// The same seed gives the same config.
+	first, err := config.NewGenerator(config.GeneratorRandOpt(rand.New(rand.NewSource(1)))).Generate(20)
+	require.NoError(t, err)
+	second, err := config.NewGenerator(config.GeneratorRandOpt(rand.New(rand.NewSource(1)))).Generate(20)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestGenerator_SmallCounts(t *testing.T) {
+	t.Parallel()
+	winner := registry.ConfigWinner(nil, nil)
+	for _, chaos := range []float64{0, 1} {
+		g := config.NewGenerator(config.GeneratorChaosLevelOpt(chaos))
+
+		// 1 Mafia and 1 Peaceful, the mafia has already won.
+		_, err := g.Generate(2)
+		assert.ErrorIs(t, err, config.CannotBalanceConfigErr)
+		for playersCount := 3; playersCount <= 8; playersCount++ {
+			cfg, err := g.Generate(playersCount)
+			require.NoError(t, err)
+			checkGeneratedConfig(t, cfg, playersCount)
+			assert.Nil(t, winner(cfg), "players count: %d", playersCount)
+		}
+	}
+
+	// By the house rules the mafia must kill everyone, so 1 Mafia and 1 Peaceful is the game.
+	houseRules := registry.ConfigWinner(nil, map[roles.Team]registry.WinCondition{
+		roles.MafiaTeam: registry.LastTeamStanding(roles.MafiaTeam),
+	})
+	cfg, err := config.NewGenerator(config.GeneratorWinnerOpt(houseRules)).Generate(2)
+	require.NoError(t, err)
+	checkGeneratedConfig(t, cfg, 2)
+}
+
+func TestGenerator_Pool(t *testing.T) {
+	t.Parallel()
+	g := config.NewGenerator(
+		config.GeneratorAllowedRolesOpt(roles.Peaceful, roles.Mafia, roles.Doctor),
+		config.GeneratorMandatoryRoleOpt(roles.Detective, 1),
+	)
+	cfg, err := g.Generate(16)
+	require.NoError(t, err)
+	checkGeneratedConfig(t, cfg, 16)
+	assert.True(t, cfg.HasRole(roles.Detective))
+	for _, roleCfg := range cfg.RolesMp {
+		assert.Contains(t, []*roles.Role{roles.Peaceful, roles.Mafia, roles.Doctor, roles.Detective}, roleCfg.Role)
+	}
+
+	_, err = config.NewGenerator(config.GeneratorMandatoryRoleOpt(roles.Mafia, 6)).Generate(5)
+	assert.ErrorIs(t, err, config.TooManyMandatoryRolesErr)
+	_, err = g.Generate(1)
+	assert.ErrorIs(t, err, config.TooFewPlayersToGenerateErr)
+	_, err = config.NewGenerator(config.GeneratorAllowedRolesOpt(roles.Peaceful)).Generate(5)
+	assert.ErrorIs(t, err, config.CannotBalanceConfigErr)
+}
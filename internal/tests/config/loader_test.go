@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigs(t *testing.T) {
+	t.Parallel()
+	t.Run("YAML", func(t *testing.T) {
+		t.Parallel()
+		file := `
+- playersCount: 5
+  roles:
+    Mafia: 1
+    Doctor: 1
+    Peaceful: 3
+- playersCount: 5
+  roles:
+    Mafia: 1
+    Peaceful: 4
+`
+		loaded, err := config.LoadConfigs(strings.NewReader(file), config.YAMLFormat)
+		require.NoError(t, err)
+		require.Len(t, *loaded[5], 2)
+		cfg := (*loaded[5])[0]
+		checkGeneratedConfig(t, cfg, 5)
+		assert.True(t, cfg.HasRole(roles.Doctor))
+		assert.Equal(t, 3, cfg.RolesMp[roles.Peaceful.Name].Count)
+	})
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+		file := `[{"playersCount": 4, "roles": {"Mafia": 1, "Peaceful": 3}}]`
+		loaded, err := config.LoadConfigs(strings.NewReader(file), config.JSONFormat)
+		require.NoError(t, err)
+		require.Len(t, *loaded[4], 1)
+		checkGeneratedConfig(t, (*loaded[4])[0], 4)
+	})
+	t.Run("All errors", func(t *testing.T) {
+		t.Parallel()
+		file := `
+- playersCount: 5
+  roles:
+    Mafia: 1
+    Peaceful: 3
+- playersCount: 3
+  roles:
+    Peaceful: 3
+- playersCount: 3
+  roles:
+    Vampire: 1
+    Mafia: 1
+    Peaceful: 1
+`
+		_, err := config.LoadConfigs(strings.NewReader(file), config.YAMLFormat,
+			config.LoadRoleChannelsOpt(roles.Doctor.Name))
+		require.Error(t, err)
+		var mErr *multierror.Error
+		require.True(t, errors.As(err, &mErr))
+		assert.Len(t, mErr.Errors, 3)
+		for _, expected := range []error{
+			config.CountsMismatchErr, config.NoRoleChannelErr, config.OneTeamConfigErr, config.UnknownRoleNameErr,
+		} {
+			assert.ErrorIs(t, err, expected)
+		}
+	})
+	t.Run("Unsupported format", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.LoadConfigs(strings.NewReader(""), "toml")
+		assert.ErrorIs(t, err, config.UnsupportedFormatErr)
+	})
+}
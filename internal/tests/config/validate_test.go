@@ -0,0 +1,82 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/registry"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfig(counts map[*roles.Role]int) *config.RolesConfig {
+	cfg := &config.RolesConfig{RolesMp: make(map[string]*config.RoleConfig)}
+	for role, count := range counts {
+		cfg.PlayersCount += count
+		cfg.RolesMp[role.Name] = &config.RoleConfig{Role: role, Count: count}
+	}
+	return cfg
+}
+
+func TestRolesConfig_Validate(t *testing.T) {
+	t.Parallel()
+	winner := config.ValidateWinnerOpt(registry.ConfigWinner(nil, nil))
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(map[*roles.Role]int{roles.Mafia: 1, roles.Don: 1, roles.Detective: 1, roles.Peaceful: 4})
+		assert.Empty(t, cfg.Validate(winner))
+		assert.NoError(t, cfg.Validate(winner).Err())
+	})
+	t.Run("Structure", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(map[*roles.Role]int{roles.Mafia: 1, roles.Peaceful: 3})
+		cfg.PlayersCount = 5
+		cfg.RolesMp["Killer"] = &config.RoleConfig{Role: roles.Mafia, Count: 0}
+		problems := cfg.Validate()
+		assert.True(t, problems.Has(config.CountsMismatchErr))
+		assert.True(t, problems.Has(config.DuplicateRoleConfigErr))
+		assert.True(t, problems.Has(config.NonPositiveCountErr))
+		assert.ErrorIs(t, problems.Err(), config.DuplicateRoleConfigErr)
+	})
+	t.Run("Gameplay", func(t *testing.T) {
+		t.Parallel()
+		problems := newConfig(map[*roles.Role]int{roles.Detective: 1, roles.Peaceful: 2}).Validate()
+		assert.True(t, problems.Has(config.OneTeamConfigErr))
+		assert.True(t, problems.Has(config.DetectiveInOneTeamGameErr))
+
+		problems = newConfig(map[*roles.Role]int{roles.Don: 1, roles.Peaceful: 4}).Validate()
+		assert.Equal(t, config.Problems{{Err: config.DonWithoutMafiaErr, Roles: []string{roles.Don.Name}}}, problems)
+	})
+	t.Run("Already won", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(map[*roles.Role]int{roles.Mafia: 2, roles.Peaceful: 2})
+		assert.Empty(t, cfg.Validate())
+		assert.True(t, cfg.Validate(winner).Has(config.AlreadyWonAtStartErr))
+
+		// House rule: mafia wins only if it is the last team.
+		houseRules := registry.ConfigWinner(
+			[]roles.Team{roles.MafiaTeam},
+			map[roles.Team]registry.WinCondition{roles.MafiaTeam: registry.LastTeamStanding(roles.MafiaTeam)},
+		)
+		assert.Empty(t, cfg.Validate(config.ValidateWinnerOpt(houseRules)))
+	})
+}
+
+func TestRolesConfig_Lint(t *testing.T) {
+	t.Parallel()
+	cfg, err := config.NewGenerator().Generate(10)
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Lint())
+
+	cfg = newConfig(map[*roles.Role]int{roles.Mafia: 3, roles.Maniac: 1, roles.Peaceful: 1})
+	problems := cfg.Lint()
+	assert.True(t, problems.Has(config.ImbalancedConfigWarning))
+	assert.True(t, problems.Has(config.TooManyMafiaWarning))
+	assert.True(t, problems.Has(config.ChaosInSmallGameWarning))
+
+	cfg = newConfig(map[*roles.Role]int{roles.Mafia: 2, roles.Peaceful: 6})
+	assert.True(t, cfg.Lint().Has(config.NoActivePeacefulWarning))
+	assert.False(t, cfg.Lint(config.LintMaxImbalanceOpt(10)).Has(config.ImbalancedConfigWarning))
+}
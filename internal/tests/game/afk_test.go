@@ -0,0 +1,78 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAFK_KickAfterMissedPhases(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(5, 1), game.AFKOpt(1, game.KickAFKPolicy))
+	require.NoError(t, err)
+	go driveClock(ctx, fakeClock(g))
+
+	// Nobody votes, so everyone with the night role is AFK after the first night.
+	errCh, infoCh := g.Run(ctx)
+	go func() {
+		for range errCh {
+		}
+	}()
+	for s := range infoCh {
+		if _, ok := s.Info.(game.FinishGameInfo); ok {
+			break
+		}
+	}
+
+	var left []player.IDType
+	for _, event := range g.GetEvents() {
+		if data, ok := event.Data.(game.PlayerDiedData); ok && data.Reason == player.Left {
+			left = append(left, data.PlayerID)
+		}
+	}
+	assert.NotEmpty(t, left)
+	for _, id := range left {
+		_, isActive := g.GetActivePlayers()[id]
+		assert.False(t, isActive)
+	}
+}
+
+func TestAFK_ReplacePlayer(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(5, 1))
+	require.NoError(t, err)
+
+	active := g.GetActivePlayers()
+	old := *active[1]
+	require.NoError(t, g.SetDisconnected(old.ID, true))
+	assert.True(t, g.IsDisconnected(old.ID))
+
+	user := player.NewNonPlayingPlayer("new", "new", "new")
+	assert.ErrorIs(t, g.ReplacePlayer(100, user), game.PlayerNotFoundErr)
+	assert.ErrorIs(t, g.ReplacePlayer(old.ID, player.NewNonPlayingPlayer(active[2].Tag, "", "")),
+		game.AlreadyInGameErr)
+	require.NoError(t, g.ReplacePlayer(old.ID, user))
+
+	replaced := g.GetActivePlayers()[old.ID]
+	assert.Equal(t, "new", replaced.Tag)
+	assert.Equal(t, old.Role, replaced.Role)
+	assert.False(t, g.IsDisconnected(old.ID))
+	startPlayers := g.GetStartPlayers()
+	assert.Contains(t, startPlayers.GetTags(), "new")
+	assert.NotContains(t, startPlayers.GetTags(), old.Tag)
+
+	events := g.GetEvents()
+	last := events[len(events)-1]
+	require.Equal(t, game.PlayerReplacedEvent, last.EventType)
+	assert.Equal(t, old.Tag, last.Data.(game.PlayerReplacedData).OldTag)
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/https-whoyan/MafiaCore/bot"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBots_FillAllSeats(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, l := lobbyHelper(t, game.LobbyLimitsOpt(5, 5))
+	bots := make(map[string]*bot.Bot)
+	for i := 1; i <= 5; i++ {
+		tag := "bot:" + strconv.Itoa(i)
+		bots[tag] = bot.New(bot.SensibleStrategy{}, bot.SeedOpt(int64(i)))
+		require.NoError(t, l.JoinBot(player.NewNonPlayingPlayer(tag, tag, tag), bots[tag]))
+	}
+	assert.ErrorIs(t, l.JoinBot(player.NewNonPlayingPlayer("bot:6", "bot:6", "bot:6"), bot.New(bot.RandomStrategy{})),
+		game.LobbyIsFullErr)
+
+	// The bots are always ready, so the countdown is going. The bots vote at once,
+	// so the timers are fired with a little delay.
+	clock := fakeClock(g)
+	go func() {
+		for {
+			if err := clock.BlockUntilContext(ctx, 1); err != nil {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+			clock.AdvanceToNext()
+		}
+	}()
+	require.NoError(t, <-l.Done())
+
+	errCh, infoCh := g.Run(ctx)
+	go func() {
+		for range errCh {
+		}
+	}()
+	for s := range infoCh {
+		if _, ok := s.Info.(game.FinishGameInfo); ok {
+			break
+		}
+	}
+
+	events := g.GetEvents()
+	finished, ok := events[len(events)-1].Data.(game.GameFinishedData)
+	require.True(t, ok)
+	require.NotNil(t, finished.Log, "the game is not finished by the bots")
+	assert.Equal(t, []player.IDType{1, 2, 3, 4, 5}, finished.Log.Bots)
+	assert.Equal(t, finished.Log.Bots, g.GetBots())
+	for _, p := range g.GetStartPlayers() {
+		assert.True(t, p.IsBot)
+		k := bots[p.Tag].GetKnowledge()
+		assert.NotNil(t, k.Role)
+		assert.Contains(t, k.Teammates, k.ID)
+	}
+}
+
+func TestBots_MafiaStrategy(t *testing.T) {
+	t.Parallel()
+	active := []player.IDType{1, 2, 3, 4, 5, 6, 7}
+	mafia := func(id player.IDType, seed int64) (*bot.Knowledge, *rand.Rand) {
+		return &bot.Knowledge{ID: id, Role: roles.Mafia, Teammates: []player.IDType{2, 5}}, rand.New(rand.NewSource(seed))
+	}
+	k1, rnd1 := mafia(2, 1)
+	k2, rnd2 := mafia(5, 2)
+
+	// The teammates choose the same target without any communication.
+	nightInfo := game.BotNightInfo{Night: 1, Role: roles.Mafia, Active: active}
+	targets := bot.MafiaStrategy{}.NightTargets(k1, nightInfo, rnd1)
+	assert.Equal(t, targets, bot.MafiaStrategy{}.NightTargets(k2, nightInfo, rnd2))
+	assert.NotContains(t, targets, player.IDType(2))
+	assert.NotContains(t, targets, player.IDType(5))
+
+	dayInfo := game.BotDayInfo{Day: 1, DayPhase: game.OpenVotingDayPhase, Active: active}
+	assert.Equal(t, bot.MafiaStrategy{}.DayTarget(k1, dayInfo, rnd1), bot.MafiaStrategy{}.DayTarget(k2, dayInfo, rnd2))
+
+	// And join the leader of the day voting, if he is not a teammate.
+	k1.DayVotes = map[player.IDType]player.IDType{1: 5, 3: 5, 4: 6}
+	assert.Equal(t, player.IDType(6), bot.MafiaStrategy{}.DayTarget(k1, dayInfo, rnd1))
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepCloneGame_JSON(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(7, 3))
+	require.NoError(t, err)
+
+	mappedPlayers := playersHelper(g.GetActivePlayers())
+	killed := mappedPlayers[roles.Peaceful][0]
+	require.NoError(t, takeANight(g, votesCfg{
+		roles.Mafia: {
+			role:  roles.Mafia,
+			votes: []player.IDType{killed.ID},
+		},
+	}))
+	g.AffectNight(g.NewNightLog())
+
+	clone, err := g.GetDeepClone()
+	require.NoError(t, err)
+	data, err := json.Marshal(clone)
+	require.NoError(t, err)
+
+	var decoded game.DeepCloneGame
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	assert.Equal(t, clone.GuildID, decoded.GuildID)
+	assert.Equal(t, clone.State, decoded.State)
+	assert.Equal(t, clone.Seed, decoded.Seed)
+	assert.Equal(t, clone.MainChannelID, decoded.MainChannelID)
+	assert.Equal(t, clone.RoleChannelIDs, decoded.RoleChannelIDs)
+	assert.Equal(t, clone.StartPlayers, decoded.StartPlayers)
+
+	require.Equal(t, clone.Active.GetIDs(), decoded.Active.GetIDs())
+	for _, id := range clone.Active.GetIDs() {
+		clonePlayer, decodedPlayer := (*clone.Active)[id], (*decoded.Active)[id]
+		// Roles are resolved to the same pointers.
+		mappedRole, _ := roles.GetRoleByName(clonePlayer.Role.Name)
+		assert.Same(t, mappedRole, decodedPlayer.Role)
+		assert.Equal(t, clonePlayer.Votes, decodedPlayer.Votes)
+		assert.Equal(t, clonePlayer.NonPlayingPlayer, decodedPlayer.NonPlayingPlayer)
+	}
+	require.Len(t, (*decoded.Dead)[roles.Peaceful], 1)
+	assert.Equal(t, killed.ID, (*decoded.Dead)[roles.Peaceful][0].ID)
+	assert.Equal(t, player.KilledAtNight, (*decoded.Dead)[roles.Peaceful][0].DeadReason)
+	for roleName, roleCfg := range decoded.RolesConfig.RolesMp {
+		mappedRole, _ := roles.GetRoleByName(roleName)
+		assert.Same(t, mappedRole, roleCfg.Role)
+		assert.Equal(t, clone.RolesConfig.RolesMp[roleName].Count, roleCfg.Count)
+	}
+
+	// Encoding is stable.
+	again, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+
+	// The decoded game can be restored.
+	_, err = restoreHelper(context.Background(), decoded)
+	assert.NoError(t, err)
+}
+
+func TestDeepCloneGame_UnsupportedVersion(t *testing.T) {
+	t.Parallel()
+	var decoded game.DeepCloneGame
+	err := json.Unmarshal([]byte(`{"version": 100}`), &decoded)
+	assert.ErrorIs(t, err, game.UnsupportedJSONVersionErr)
+}
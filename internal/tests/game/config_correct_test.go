@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/registry"
 	"github.com/https-whoyan/MafiaCore/roles"
 
 	"github.com/stretchr/testify/assert"
@@ -46,6 +48,12 @@ func Test_Config_correct(t *testing.T) {
 				}
 			}
 
+			// Configs for 2-4 players are only for tests, one team has already won in them.
+			if exceptedPlayersCount >= 5 {
+				winner := registry.ConfigWinner(nil, nil)
+				assert.Empty(t, cfg.Validate(config.ValidateWinnerOpt(winner)), "index: %d", i)
+			}
+
 			defer func() {
 				if r := recover(); r != nil {
 					assert.Failf(t, fmt.Sprintf("recovered from panic in config at index: %d", i), "")
@@ -66,3 +74,32 @@ func Test_Config_correct(t *testing.T) {
 		}
 	}
 }
+
+func TestInit_ValidateConfigOpt(t *testing.T) {
+	cfg := &config.RolesConfig{
+		PlayersCount: 4,
+		RolesMp: map[string]*config.RoleConfig{
+			roles.Mafia.Name:    {Role: roles.Mafia, Count: 2},
+			roles.Peaceful.Name: {Role: roles.Peaceful, Count: 2},
+		},
+	}
+	_, err := initHelper(cfg)
+	assert.NoError(t, err)
+	_, err = initHelper(cfg, game.ValidateConfigOpt())
+	assert.ErrorIs(t, err, game.InvalidConfigErr)
+	assert.ErrorIs(t, err, config.AlreadyWonAtStartErr)
+
+	// The config with the unknown role is rejected, not panics.
+	cfg = &config.RolesConfig{
+		PlayersCount: 5,
+		RolesMp: map[string]*config.RoleConfig{
+			roles.Mafia.Name:    {Role: roles.Mafia, Count: 1},
+			roles.Peaceful.Name: {Role: roles.Peaceful, Count: 3},
+			"Unknown":           {Role: nil, Count: 1},
+		},
+	}
+	_, err = initHelper(cfg, game.ValidateConfigOpt())
+	assert.ErrorIs(t, err, game.InvalidConfigErr)
+	assert.ErrorIs(t, err, config.UnknownRoleNameErr)
+	assert.Empty(t, cfg.Lint())
+}
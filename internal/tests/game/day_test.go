@@ -1,12 +1,18 @@
 package game
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"strconv"
 	"testing"
 
 	"github.com/https-whoyan/MafiaCore/config"
 	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // SeeCalculationDayDeadline Designed to look at giving time for the day under certain circumstances.
@@ -72,3 +78,403 @@ func Test_SeeCalculationDayDeadline(t *testing.T) {
 func Test_Day(t *testing.T) {
 	t.Parallel()
 }
+
+// dayVotes presents the votes of the players in each voting phase of the day.
+// Key - voter ID, value - vote (game.EmptyVoteInt for the empty vote).
+type dayVotes map[game.DayPhase]map[player.IDType]player.IDType
+
+func activeIDs(g *game.Game) []player.IDType {
+	active := g.GetActivePlayers()
+	return active.GetIDs()
+}
+
+// takeADay plays the day, sets the votes of v, and returns the phases of the day in order.
+func takeADay(t *testing.T, g *game.Game, v dayVotes) (game.DayLog, []game.DayPhase) {
+	clock := fakeClock(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for {
+			select {
+			case <-g.GetErrorChan():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var phases []game.DayPhase
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case s := <-g.GetInfoChan():
+				info, ok := s.Info.(game.SwitchDayPhaseInfo)
+				if !ok {
+					continue
+				}
+				phases = append(phases, info.DayPhase)
+				if info.DayPhase == game.DefenseDayPhase {
+					assert.NotNil(t, info.Speaker)
+					_ = clock.BlockUntilContext(ctx, 1)
+					clock.AdvanceToNext()
+					continue
+				}
+				for voterID, vote := range v[info.DayPhase] {
+					assert.NoError(t, g.SetDayVote(&game.OneVoteProvider{
+						VotedPlayerID: strconv.Itoa(int(voterID)),
+						Vote:          strconv.Itoa(int(vote)),
+					}))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	dayLog := g.Day()
+	cancel()
+	<-done
+	return dayLog, phases
+}
+
+func Test_NominationDay(t *testing.T) {
+	t.Parallel()
+	cfg := config.GetConfigByPlayersCountAndIndex(5, 1)
+
+	t.Run("Nominee is kicked", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.DayProcedureOpt(game.NominationDayProcedure))
+		require.NoError(t, err)
+		ids := activeIDs(g)
+		a, b := ids[0], ids[1]
+
+		dayLog, phases := takeADay(t, g, dayVotes{
+			game.NominationDayPhase:  {a: b, b: a, ids[2]: a, ids[3]: a, ids[4]: a},
+			game.FinalVotingDayPhase: {a: b, b: a, ids[2]: a, ids[3]: a, ids[4]: a},
+		})
+		assert.Equal(t, []player.IDType{a, b}, dayLog.Nominees)
+		assert.Empty(t, dayLog.Runoff)
+		assert.False(t, dayLog.IsSkip)
+		require.NotNil(t, dayLog.Kicked)
+		assert.Equal(t, a, *dayLog.Kicked)
+		assert.Equal(t, []game.DayPhase{
+			game.NominationDayPhase, game.DefenseDayPhase, game.DefenseDayPhase, game.FinalVotingDayPhase,
+		}, phases)
+	})
+	t.Run("Runoff after the tie", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.DayProcedureOpt(game.NominationDayProcedure))
+		require.NoError(t, err)
+		ids := activeIDs(g)
+		a, b := ids[0], ids[1]
+
+		dayLog, phases := takeADay(t, g, dayVotes{
+			game.NominationDayPhase:  {a: b, b: a, ids[2]: a, ids[3]: a, ids[4]: a},
+			game.FinalVotingDayPhase: {a: b, b: a, ids[2]: a, ids[3]: b, ids[4]: game.EmptyVoteInt},
+			game.RunoffDayPhase:      {a: b, b: a, ids[2]: b, ids[3]: b, ids[4]: b},
+		})
+		assert.Equal(t, []player.IDType{a, b}, dayLog.Runoff)
+		require.NotNil(t, dayLog.Kicked)
+		assert.Equal(t, b, *dayLog.Kicked)
+		assert.Equal(t, game.RunoffDayPhase, phases[len(phases)-1])
+	})
+	t.Run("Empty votes in the final vote", func(t *testing.T) {
+		t.Parallel()
+		votes := func(ids []player.IDType) dayVotes {
+			a, b := ids[0], ids[1]
+			return dayVotes{
+				game.NominationDayPhase: {a: b, b: a, ids[2]: a, ids[3]: a, ids[4]: a},
+				game.FinalVotingDayPhase: {
+					a: game.EmptyVoteInt, b: game.EmptyVoteInt, ids[2]: a,
+					ids[3]: game.EmptyVoteInt, ids[4]: game.EmptyVoteInt,
+				},
+			}
+		}
+
+		g, err := initHelper(cfg, game.DayProcedureOpt(game.NominationDayProcedure))
+		require.NoError(t, err)
+		dayLog, _ := takeADay(t, g, votes(activeIDs(g)))
+		assert.True(t, dayLog.IsSkip)
+		assert.Empty(t, dayLog.Runoff)
+
+		g, err = initHelper(cfg, game.DayProcedureOpt(game.NominationDayProcedure),
+			game.DayVotingRulesOpt(game.DayVotingRules{IgnoreEmptyVotes: true}))
+		require.NoError(t, err)
+		ids := activeIDs(g)
+		dayLog, _ = takeADay(t, g, votes(ids))
+		require.NotNil(t, dayLog.Kicked)
+		assert.Equal(t, ids[0], *dayLog.Kicked)
+	})
+	t.Run("Nobody is nominated", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.DayProcedureOpt(game.NominationDayProcedure))
+		require.NoError(t, err)
+		ids := activeIDs(g)
+
+		votes := make(map[player.IDType]player.IDType)
+		for _, id := range ids {
+			votes[id] = game.EmptyVoteInt
+		}
+		dayLog, _ := takeADay(t, g, dayVotes{game.NominationDayPhase: votes})
+		assert.True(t, dayLog.IsSkip)
+		assert.Empty(t, dayLog.Nominees)
+	})
+	t.Run("Vote for not a candidate", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.DayProcedureOpt(game.NominationDayProcedure))
+		require.NoError(t, err)
+		ids := activeIDs(g)
+		a, b := ids[0], ids[1]
+
+		nominations := map[player.IDType]player.IDType{a: b, b: a, ids[2]: a, ids[3]: a, ids[4]: a}
+		clock := fakeClock(g)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			for {
+				select {
+				case <-g.GetErrorChan():
+				case s := <-g.GetInfoChan():
+					info, ok := s.Info.(game.SwitchDayPhaseInfo)
+					if !ok {
+						continue
+					}
+					switch info.DayPhase {
+					case game.DefenseDayPhase:
+						_ = clock.BlockUntilContext(ctx, 1)
+						clock.AdvanceToNext()
+					case game.NominationDayPhase:
+						for voterID, vote := range nominations {
+							assert.NoError(t, g.SetDayVote(&game.OneVoteProvider{
+								VotedPlayerID: strconv.Itoa(int(voterID)),
+								Vote:          strconv.Itoa(int(vote)),
+							}))
+						}
+					case game.FinalVotingDayPhase:
+						assert.ErrorIs(t, g.SetDayVote(&game.OneVoteProvider{
+							VotedPlayerID: strconv.Itoa(int(a)),
+							Vote:          strconv.Itoa(int(ids[2])),
+						}), game.NotCandidateErr)
+						_ = clock.BlockUntilContext(ctx, 1)
+						clock.AdvanceToNext()
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		dayLog := g.Day()
+		assert.True(t, dayLog.IsSkip)
+	})
+}
+
+func Test_DayVotingRules(t *testing.T) {
+	t.Parallel()
+	cfg := config.GetConfigByPlayersCountAndIndex(5, 1)
+
+	testCases := []struct {
+		name     string
+		rules    game.DayVotingRules
+		votes    func(ids []player.IDType) dayVotes
+		expected func(t *testing.T, ids []player.IDType, l game.DayLog)
+	}{
+		{
+			name: "Tie means skip by default",
+			votes: func(ids []player.IDType) dayVotes {
+				return dayVotes{game.OpenVotingDayPhase: {
+					ids[0]: ids[1], ids[1]: ids[0], ids[2]: ids[0], ids[3]: ids[1], ids[4]: game.EmptyVoteInt,
+				}}
+			},
+			expected: func(t *testing.T, ids []player.IDType, l game.DayLog) {
+				assert.True(t, l.IsSkip)
+				assert.Equal(t, []player.IDType{ids[0], ids[1]}, l.Tied)
+			},
+		},
+		{
+			name:  "Kill all tied",
+			rules: game.DayVotingRules{TieBreak: game.KillAllDayTieBreak},
+			votes: func(ids []player.IDType) dayVotes {
+				return dayVotes{game.OpenVotingDayPhase: {
+					ids[0]: ids[1], ids[1]: ids[0], ids[2]: ids[0], ids[3]: ids[1], ids[4]: game.EmptyVoteInt,
+				}}
+			},
+			expected: func(t *testing.T, ids []player.IDType, l game.DayLog) {
+				assert.False(t, l.IsSkip)
+				assert.Equal(t, []player.IDType{ids[0], ids[1]}, l.GetKicked())
+			},
+		},
+		{
+			name:  "Random among tied",
+			rules: game.DayVotingRules{TieBreak: game.RandomDayTieBreak},
+			votes: func(ids []player.IDType) dayVotes {
+				return dayVotes{game.OpenVotingDayPhase: {
+					ids[0]: ids[1], ids[1]: ids[0], ids[2]: ids[0], ids[3]: ids[1], ids[4]: game.EmptyVoteInt,
+				}}
+			},
+			expected: func(t *testing.T, ids []player.IDType, l game.DayLog) {
+				require.NotNil(t, l.Kicked)
+				assert.Contains(t, []player.IDType{ids[0], ids[1]}, *l.Kicked)
+			},
+		},
+		{
+			name:  "Runoff between tied",
+			rules: game.DayVotingRules{TieBreak: game.RunoffDayTieBreak},
+			votes: func(ids []player.IDType) dayVotes {
+				return dayVotes{
+					game.OpenVotingDayPhase: {
+						ids[0]: ids[1], ids[1]: ids[0], ids[2]: ids[0], ids[3]: ids[1], ids[4]: game.EmptyVoteInt,
+					},
+					game.RunoffDayPhase: {
+						ids[0]: ids[1], ids[1]: ids[0], ids[2]: ids[0], ids[3]: ids[0], ids[4]: ids[0],
+					},
+				}
+			},
+			expected: func(t *testing.T, ids []player.IDType, l game.DayLog) {
+				assert.Equal(t, []player.IDType{ids[0], ids[1]}, l.Runoff)
+				require.NotNil(t, l.Kicked)
+				assert.Equal(t, ids[0], *l.Kicked)
+			},
+		},
+		{
+			name:  "Absolute majority, empty votes skip",
+			rules: game.DayVotingRules{Majority: game.AbsoluteDayMajority},
+			votes: func(ids []player.IDType) dayVotes {
+				return dayVotes{game.OpenVotingDayPhase: {
+					ids[0]: ids[1], ids[1]: ids[0], ids[2]: ids[0],
+					ids[3]: game.EmptyVoteInt, ids[4]: game.EmptyVoteInt,
+				}}
+			},
+			expected: func(t *testing.T, ids []player.IDType, l game.DayLog) {
+				assert.True(t, l.IsSkip)
+			},
+		},
+		{
+			name:  "Absolute majority, empty votes ignored",
+			rules: game.DayVotingRules{Majority: game.AbsoluteDayMajority, IgnoreEmptyVotes: true},
+			votes: func(ids []player.IDType) dayVotes {
+				return dayVotes{game.OpenVotingDayPhase: {
+					ids[0]: ids[1], ids[1]: ids[0], ids[2]: ids[0],
+					ids[3]: game.EmptyVoteInt, ids[4]: game.EmptyVoteInt,
+				}}
+			},
+			expected: func(t *testing.T, ids []player.IDType, l game.DayLog) {
+				require.NotNil(t, l.Kicked)
+				assert.Equal(t, ids[0], *l.Kicked)
+				assert.Equal(t, game.AbsoluteDayMajority, l.Rules.Majority)
+			},
+		},
+		{
+			name:  "Two-thirds majority is not reached",
+			rules: game.DayVotingRules{Majority: game.TwoThirdsDayMajority, IgnoreEmptyVotes: true},
+			votes: func(ids []player.IDType) dayVotes {
+				return dayVotes{game.OpenVotingDayPhase: {
+					ids[0]: ids[1], ids[1]: ids[0], ids[2]: ids[0], ids[3]: ids[2], ids[4]: game.EmptyVoteInt,
+				}}
+			},
+			expected: func(t *testing.T, ids []player.IDType, l game.DayLog) {
+				assert.True(t, l.IsSkip)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			g, err := initHelper(cfg, game.DayVotingRulesOpt(testCase.rules))
+			require.NoError(t, err)
+			ids := activeIDs(g)
+
+			dayLog, _ := takeADay(t, g, testCase.votes(ids))
+			testCase.expected(t, ids, dayLog)
+		})
+	}
+}
+
+func Test_DayVoteTally(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(5, 1))
+	require.NoError(t, err)
+	ids := activeIDs(g)
+	a, b, c := ids[0], ids[1], ids[2]
+	setVote := func(voter, vote player.IDType) error {
+		return g.SetDayVote(game.NewVoteProvider(strconv.Itoa(int(voter)), strconv.Itoa(int(vote)), false, false))
+	}
+	withdraw := func(voter player.IDType) error {
+		return g.WithdrawDayVote(strconv.Itoa(int(voter)), false)
+	}
+	assert.Equal(t, game.NoDayPhase, g.GetDayVoteTally().DayPhase)
+	assert.ErrorIs(t, withdraw(a), game.IncorrectVoteTimeErr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for {
+			select {
+			case <-g.GetErrorChan():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var tallies []game.DayVoteTally
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case s := <-g.GetInfoChan():
+				switch info := s.Info.(type) {
+				case game.DayResultInfo:
+					return
+				case game.DayVoteTallyChangedInfo:
+					tallies = append(tallies, info.Tally)
+				case game.SwitchDayPhaseInfo:
+					if info.DayPhase != game.OpenVotingDayPhase {
+						continue
+					}
+					assert.NoError(t, setVote(a, b))
+					tally := g.GetDayVoteTally()
+					assert.Equal(t, game.OpenVotingDayPhase, tally.DayPhase)
+					assert.Equal(t, []player.IDType{a}, tally.Votes[b])
+					assert.Equal(t, []player.IDType{b}, tally.Leaders)
+					assert.Equal(t, ids[1:], tally.NotVoted)
+
+					result := g.SubmitDayVote(game.NewVoteProvider(
+						strconv.Itoa(int(a)), strconv.Itoa(int(c)), false, false))
+					assert.Equal(t, game.VoteResult{Status: game.VoteSuperseded}, result)
+					tally = g.GetDayVoteTally()
+					assert.Empty(t, tally.Votes[b])
+					assert.Equal(t, []player.IDType{a}, tally.Votes[c])
+
+					assert.NoError(t, withdraw(a))
+					assert.ErrorIs(t, withdraw(a), game.NotVotedErr)
+					assert.Equal(t, ids, g.GetDayVoteTally().NotVoted)
+
+					// The voting may be decided before the last votes.
+					for _, voter := range ids {
+						if voter == b {
+							_ = setVote(voter, a)
+						} else {
+							_ = setVote(voter, b)
+						}
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	dayLog := g.Day()
+	require.NotNil(t, dayLog.Kicked)
+	assert.Equal(t, b, *dayLog.Kicked)
+	assert.Equal(t, game.NoDayPhase, g.GetDayVoteTally().DayPhase)
+	// The result is the last signal of the day.
+	g.AffectDay(dayLog)
+	<-done
+
+	require.Greater(t, len(tallies), 3)
+	assert.Equal(t, []player.IDType{a}, tallies[0].Votes[b])
+	assert.Equal(t, []player.IDType{a}, tallies[1].Votes[c])
+	assert.Empty(t, tallies[2].Votes)
+}
@@ -0,0 +1,140 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_PauseExtendSkip(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(7, 3))
+	require.NoError(t, err)
+	clock := fakeClock(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.ErrorIs(t, g.ExtendDeadline(time.Minute), game.NoCurrentTimerErr)
+	assert.ErrorIs(t, g.Resume(), game.NotPausedErr)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		isFirst := true
+		for {
+			select {
+			case <-g.GetErrorChan():
+			case s := <-g.GetInfoChan():
+				votedRole := signalHandler(s)
+				if votedRole == nil {
+					continue
+				}
+				require.NoError(t, clock.BlockUntilContext(ctx, 1))
+				if isFirst {
+					isFirst = false
+					require.NoError(t, g.Pause())
+					assert.ErrorIs(t, g.Pause(), game.AlreadyPausedErr)
+					assert.Equal(t, 0, clock.Waiters())
+					require.NoError(t, g.ExtendDeadline(time.Minute))
+					require.NoError(t, g.Resume())
+					d, ok := clock.AdvanceToNext()
+					assert.True(t, ok)
+					assert.Equal(t, g.GetTimings().GetVotingDeadline(votedRole.Name)+time.Minute, d)
+					continue
+				}
+				require.NoError(t, g.SkipCurrentVoting())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	g.Night()
+	cancel()
+	<-done
+
+	var actions []game.HostAction
+	expired := 0
+	for _, e := range g.GetEvents() {
+		switch data := e.Data.(type) {
+		case game.HostActionData:
+			actions = append(actions, data.Action)
+		case game.TimerExpiredData:
+			expired++
+		}
+	}
+	require.Greater(t, len(actions), 3)
+	assert.Equal(t, []game.HostAction{game.PauseHostAction, game.ExtendDeadlineHostAction, game.ResumeHostAction},
+		actions[:3])
+	for _, action := range actions[3:] {
+		assert.Equal(t, game.SkipHostAction, action)
+	}
+	// Only the first voting is ended by the deadline.
+	assert.Equal(t, 1, expired)
+}
+
+func TestHost_ForceKillAndRevive(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(5, 1))
+	require.NoError(t, err)
+	killed := playersHelper(g.GetActivePlayers())[roles.Peaceful][0].ID
+	assert.ErrorIs(t, g.Revive(killed), game.PlayerIsNotDeadErr)
+	go driveClock(ctx, fakeClock(g))
+
+	errCh, infoCh := g.Run(ctx)
+	go func() {
+		for range errCh {
+		}
+	}()
+	isKilled := false
+	for s := range infoCh {
+		// Nobody votes, so the game is stopped after the revival.
+		switch info := s.Info.(type) {
+		case game.SwitchStateInfo:
+			if info.NewState == game.NightState && !isKilled {
+				isKilled = true
+				require.NoError(t, g.ForceKill(killed, ""))
+			}
+		case game.PlayerDiedInfo:
+			if info.PlayerID == killed {
+				assert.Equal(t, player.KilledByHost, info.Reason)
+				require.NoError(t, g.Revive(killed))
+			}
+		case game.PlayerRevivedInfo:
+			cancel()
+		}
+		if _, ok := s.Info.(game.FinishGameInfo); ok {
+			break
+		}
+	}
+
+	var died, revived bool
+	events := g.GetEvents()
+	for _, e := range events {
+		switch data := e.Data.(type) {
+		case game.PlayerDiedData:
+			if data.PlayerID == killed && data.Reason == player.KilledByHost {
+				died = true
+			}
+		case game.PlayerRevivedData:
+			assert.True(t, died)
+			assert.Equal(t, killed, data.PlayerID)
+			revived = true
+		}
+	}
+	assert.True(t, died)
+	assert.True(t, revived)
+
+	_, err = game.Replay(events)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,181 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_Replay(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(7, 3))
+	require.NoError(t, err)
+
+	mappedPlayers := playersHelper(g.GetActivePlayers())
+	doctor := mappedPlayers[roles.Doctor][0]
+	killed := mappedPlayers[roles.Peaceful][0]
+	vCfg := votesCfg{
+		roles.Whore: {
+			role:  roles.Whore,
+			votes: []player.IDType{doctor.ID},
+		},
+		roles.Mafia: {
+			role:  roles.Mafia,
+			votes: []player.IDType{killed.ID},
+		},
+		roles.Don: {
+			role:  roles.Don,
+			votes: []player.IDType{nonVote},
+		},
+	}
+	require.NoError(t, takeANight(g, vCfg))
+
+	// The night voting is over, so the vote is rejected.
+	active := g.GetActivePlayers()
+	lateVote := voteCfg{role: roles.Mafia, votes: []player.IDType{doctor.ID}}
+	result := g.SubmitNightVote(lateVote.toVotePr(&active))
+	assert.Equal(t, game.VoteTooLate, result.Status)
+	require.ErrorIs(t, result.Err, game.VoteTooLateErr)
+	g.AffectNight(g.NewNightLog())
+
+	events := g.GetEvents()
+	require.NotEmpty(t, events)
+	assert.Equal(t, game.GameStartedEvent, events[0].EventType)
+
+	replayed, err := game.Replay(events)
+	require.NoError(t, err)
+	actual, err := g.GetDeepClone()
+	require.NoError(t, err)
+
+	assert.Equal(t, actual.State, replayed.State)
+	assert.Equal(t, actual.NightCounter, replayed.NightCounter)
+	require.Equal(t, actual.Active.GetIDs(), replayed.Active.GetIDs())
+	for _, id := range actual.Active.GetIDs() {
+		actualPlayer, replayedPlayer := (*actual.Active)[id], (*replayed.Active)[id]
+		assert.Equal(t, actualPlayer.Role.Name, replayedPlayer.Role.Name)
+		assert.Equal(t, actualPlayer.Votes, replayedPlayer.Votes)
+		assert.Equal(t, actualPlayer.LifeStatus, replayedPlayer.LifeStatus)
+		assert.Equal(t, actualPlayer.InteractionStatus, replayedPlayer.InteractionStatus)
+	}
+	assert.Equal(t, actual.Dead.Len(), replayed.Dead.Len())
+	assert.Nil(t, replayed.Active.GetByIDType(killed.ID))
+
+	var (
+		isMutedAtSomePoint bool
+		rejected           *game.VoteRejectedData
+	)
+	for i, e := range events {
+		switch data := e.Data.(type) {
+		case game.PlayerStatusChangedData:
+			if data.PlayerID != doctor.ID || data.InteractionStatus != player.Muted {
+				continue
+			}
+			// Replay to the mute.
+			replayedToMute, replayErr := game.Replay(events[:i+1])
+			require.NoError(t, replayErr)
+			assert.Equal(t, player.Muted, replayedToMute.Active.GetByIDType(doctor.ID).InteractionStatus)
+			isMutedAtSomePoint = true
+		case game.VoteRejectedData:
+			rejected = &data
+		}
+	}
+	assert.True(t, isMutedAtSomePoint)
+	require.NotNil(t, rejected)
+	assert.True(t, errors.Is(rejected.Err, result.Err))
+
+	_, err = game.Replay(events[1:])
+	assert.ErrorIs(t, err, game.JournalIsNotStartedErr)
+}
+
+func TestJournal_JSON(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(7, 3))
+	require.NoError(t, err)
+	mappedPlayers := playersHelper(g.GetActivePlayers())
+	require.NoError(t, takeANight(g, votesCfg{
+		roles.Mafia: {
+			role:  roles.Mafia,
+			votes: []player.IDType{mappedPlayers[roles.Peaceful][0].ID},
+		},
+	}))
+	g.AffectNight(g.NewNightLog())
+
+	events := g.GetEvents()
+	data, err := json.Marshal(events)
+	require.NoError(t, err)
+	var decoded []game.Event
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded, len(events))
+	for i := range events {
+		assert.Equal(t, events[i].EventType, decoded[i].EventType)
+		assert.IsType(t, events[i].Data, decoded[i].Data)
+	}
+
+	expected, err := game.Replay(events)
+	require.NoError(t, err)
+	replayed, err := game.Replay(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, expected.State, replayed.State)
+	assert.Equal(t, expected.NightCounter, replayed.NightCounter)
+	require.Equal(t, expected.Active.GetIDs(), replayed.Active.GetIDs())
+	for _, id := range expected.Active.GetIDs() {
+		expectedPlayer, replayedPlayer := (*expected.Active)[id], (*replayed.Active)[id]
+		// The roles are resolved to the same pointers.
+		assert.Same(t, expectedPlayer.Role, replayedPlayer.Role)
+		assert.Equal(t, expectedPlayer.Votes, replayedPlayer.Votes)
+		assert.Equal(t, expectedPlayer.LifeStatus, replayedPlayer.LifeStatus)
+	}
+	assert.Equal(t, expected.Dead.Len(), replayed.Dead.Len())
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"eventType": 200}`), &game.Event{}), game.UnknownEventTypeErr)
+}
+
+// eventStorage reads the game, while it saves the events.
+type eventStorage struct {
+	nightSnapshotStorage
+	g     *game.Game
+	mu    sync.Mutex
+	saved []game.Event
+}
+
+func (s *eventStorage) SaveEvent(_ context.Context, _ string, e game.Event) error {
+	// The event is saved not under the game lock.
+	_ = s.g.GetActivePlayers()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = append(s.saved, e)
+	return nil
+}
+
+func TestJournal_EventStorage(t *testing.T) {
+	t.Parallel()
+	storage := &eventStorage{}
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(7, 3), game.StorageOpt(storage))
+	require.NoError(t, err)
+	storage.g = g
+	require.NoError(t, takeANight(g, votesCfg{}))
+	g.AffectNight(g.NewNightLog())
+
+	events := g.GetEvents()
+	assert.Eventually(t, func() bool {
+		storage.mu.Lock()
+		defer storage.mu.Unlock()
+		return len(storage.saved) >= len(events)
+	}, time.Second, time.Millisecond)
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	for i, e := range storage.saved[:len(events)] {
+		assert.Equal(t, i, e.Seq)
+	}
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/https-whoyan/MafiaCore/internal/tests/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastWords(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(5, 1))
+	require.NoError(t, err)
+
+	mappedPlayers := playersHelper(g.GetActivePlayers())
+	killed := mappedPlayers[roles.Peaceful][0]
+	require.NoError(t, takeANight(g, votesCfg{
+		roles.Mafia: {
+			role:  roles.Mafia,
+			votes: []player.IDType{killed.ID},
+		},
+	}))
+	nightLog := g.NewNightLog()
+	require.Equal(t, []player.IDType{killed.ID}, nightLog.Dead)
+	g.AffectNight(nightLog)
+
+	mainChannel := g.GetMainChannel().(*models.TestMainChannel)
+	clock := fakeClock(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		states   []game.State
+		speakers []player.IDType
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-g.GetErrorChan():
+			case s := <-g.GetInfoChan():
+				switch info := s.Info.(type) {
+				case game.SwitchStateInfo:
+					states = append(states, info.NewState)
+				case game.LastWordsInfo:
+					speakers = append(speakers, info.Speaker)
+					// Only the dying player may speak.
+					assert.Equal(t, []string{killed.Tag}, mainChannel.Speakers)
+					_ = clock.BlockUntilContext(ctx, 1)
+					clock.AdvanceToNext()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	g.LastWords(nightLog.Dead)
+	cancel()
+	<-done
+
+	assert.Equal(t, []game.State{game.LastWordsState}, states)
+	assert.Equal(t, []player.IDType{killed.ID}, speakers)
+	assert.Nil(t, mainChannel.Speakers)
+
+	events := g.GetEvents()
+	last := events[len(events)-1]
+	require.Equal(t, game.SpectatorsMovedEvent, last.EventType)
+	assert.Equal(t, []string{killed.Tag}, last.Data.(game.SpectatorsMovedData).Tags)
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/https-whoyan/MafiaCore/channel"
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	timePack "github.com/https-whoyan/MafiaCore/time"
+
+	"github.com/https-whoyan/MafiaCore/internal/tests/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lobbyHelper(t *testing.T, opts ...game.LobbyOption) (*game.Game, *game.Lobby) {
+	g := game.GetNewGame(context.Background(), models.TestingGuildID,
+		game.FMTerOpt(models.TestFMTInstance),
+		game.RenamePrOpt(models.TestRenameUserProviderInstance),
+		game.ClockOpt(timePack.NewFakeClock(time.Now())),
+	)
+	require.NoError(t, g.SetMainChannel(models.NewTestMainChannels()))
+	for _, roleCh := range models.NewTestChannels() {
+		require.NoError(t, g.SetRoleChannels(channel.RoleChannel(roleCh)))
+	}
+	l, err := game.NewLobby(g, opts...)
+	require.NoError(t, err)
+	return g, l
+}
+
+func TestLobby(t *testing.T) {
+	t.Parallel()
+	g, l := lobbyHelper(t, game.LobbyLimitsOpt(5, 6))
+	assert.Equal(t, game.State(game.RegisterState), g.GetState())
+
+	players := *models.GetTestPlayers(7)
+	for _, p := range players[:6] {
+		require.NoError(t, l.Join(p))
+	}
+	assert.ErrorIs(t, l.Join(players[0]), game.AlreadyInLobbyErr)
+	assert.ErrorIs(t, l.Join(players[6]), game.LobbyIsFullErr)
+	require.NoError(t, l.JoinAsSpectator(players[6]))
+	assert.ErrorIs(t, l.SetReady("unknown", true), game.NotInLobbyErr)
+
+	// One player leaves, the configs are for 5 players now.
+	require.NoError(t, l.Leave(players[5].Tag))
+	configs, err := l.GetConfigs()
+	require.NoError(t, err)
+	require.Equal(t, config.GetConfigByPlayersCountAndIndex(5, 0), configs[0])
+	assert.ErrorIs(t, l.VoteForConfig(players[0].Tag, len(configs)), game.IncorrectConfigIndexErr)
+	for _, p := range players[:3] {
+		require.NoError(t, l.VoteForConfig(p.Tag, 1))
+	}
+
+	// The countdown starts only when everyone is ready, and stops, if anyone is not.
+	for _, p := range players[:5] {
+		assert.False(t, l.IsCountdown())
+		require.NoError(t, l.SetReady(p.Tag, true))
+	}
+	assert.True(t, l.IsCountdown())
+	require.NoError(t, l.SetReady(players[0].Tag, false))
+	assert.False(t, l.IsCountdown())
+	require.NoError(t, l.SetReady(players[0].Tag, true))
+
+	clock := fakeClock(g)
+	clock.BlockUntil(1)
+	clock.AdvanceToNext()
+	require.NoError(t, <-l.Done())
+
+	assert.Equal(t, game.State(game.StartingState), g.GetState())
+	assert.Same(t, configs[1], g.GetConfig())
+	assert.Len(t, g.GetActivePlayers(), 5)
+	assert.Len(t, g.GetSpectatorsOfGame(), 1)
+	assert.ErrorIs(t, l.Join(players[5]), game.LobbyIsClosedErr)
+}
+
+func TestLobby_StartFails(t *testing.T) {
+	t.Parallel()
+	// The game without the context and the role channels.
+	g := game.GetNewGame(nil, models.TestingGuildID,
+		game.FMTerOpt(models.TestFMTInstance),
+		game.RenamePrOpt(models.TestRenameUserProviderInstance),
+		game.ClockOpt(timePack.NewFakeClock(time.Now())),
+	)
+	require.NoError(t, g.SetMainChannel(models.NewTestMainChannels()))
+	l, err := game.NewLobby(g, game.LobbyLimitsOpt(5, 5))
+	require.NoError(t, err)
+
+	players := *models.GetTestPlayers(5)
+	for _, p := range players {
+		require.NoError(t, l.Join(p))
+		require.NoError(t, l.SetReady(p.Tag, true))
+	}
+	clock := fakeClock(g)
+	// start ends the countdown and waits for the start.
+	start := func() {
+		require.True(t, l.IsCountdown())
+		clock.BlockUntil(1)
+		clock.AdvanceToNext()
+		require.Eventually(t, func() bool { return !l.IsCountdown() }, time.Second, time.Millisecond)
+	}
+	restart := func() {
+		require.NoError(t, l.SetReady(players[0].Tag, false))
+		require.NoError(t, l.SetReady(players[0].Tag, true))
+		start()
+	}
+
+	// The start fails twice, the unread result is replaced, and the lobby stays open.
+	start()
+	restart()
+	assert.ErrorIs(t, <-l.Done(), game.NotFullRoleChannelInfoErr)
+	assert.Empty(t, l.Done())
+	assert.Equal(t, game.State(game.RegisterState), g.GetState())
+
+	for _, roleCh := range models.NewTestChannels() {
+		require.NoError(t, g.SetRoleChannels(channel.RoleChannel(roleCh)))
+	}
+	restart()
+	require.NoError(t, <-l.Done())
+	assert.Equal(t, game.State(game.StartingState), g.GetState())
+	assert.ErrorIs(t, l.Join(players[0]), game.LobbyIsClosedErr)
+}
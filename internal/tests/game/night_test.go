@@ -3,6 +3,7 @@ package game
 import (
 	"context"
 	"github.com/samber/lo"
+	"strconv"
 	"sync"
 	"testing"
 
@@ -22,7 +23,11 @@ func TestNightCheckDeadlock_EmptyVotes(t *testing.T) {
 		if err != nil {
 			require.Fail(t, err.Error())
 		}
-		errCh, infoCh := g.Run(context.Background())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go driveClock(ctx, fakeClock(g))
+
+		errCh, infoCh := g.Run(ctx)
 		go func() {
 			for range errCh {
 			}
@@ -186,7 +191,8 @@ func TestNightConfig7_3(t *testing.T) {
 	})
 	t.Run("Excepted No dies, 3", func(t *testing.T) {
 		t.Parallel()
-		g, err := initHelper(testedCfg)
+		// Doctor heals himself.
+		g, err := initHelper(testedCfg, game.VoteForYourselfOpt(true))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -1044,7 +1050,7 @@ func TestReincarnation(t *testing.T) {
 		nightLog := g.NewNightLog()
 
 		wg := sync.WaitGroup{}
-		wg.Add(2)
+		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			g.AffectNight(nightLog)
@@ -1057,3 +1063,318 @@ func TestReincarnation(t *testing.T) {
 		assert.Equal(t, exceptedDoneRole, actualDonRole)
 	})
 }
+
+func TestNightVoteResults(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(5, 1))
+	require.NoError(t, err)
+	mappedPlayers := playersHelper(g.GetActivePlayers())
+	killed, mafia := mappedPlayers[roles.Peaceful][0], mappedPlayers[roles.Mafia][0]
+	mafiaVote := func(vote string) game.VoteResult {
+		return g.SubmitNightVote(game.NewVoteProvider(strconv.Itoa(int(mafia.ID)), vote, false, false))
+	}
+
+	clock := fakeClock(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for {
+			select {
+			case <-g.GetErrorChan():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case s := <-g.GetInfoChan():
+				votedRole := signalHandler(s)
+				if votedRole == nil {
+					continue
+				}
+				if votedRole != roles.Mafia {
+					_ = clock.BlockUntilContext(ctx, 1)
+					clock.AdvanceToNext()
+					continue
+				}
+				result := mafiaVote("100")
+				assert.Equal(t, game.VoteRejected, result.Status)
+				assert.ErrorIs(t, result.Err, game.IncorrectVoteType)
+				// The vote does not wait for the game.
+				assert.Equal(t, game.VoteResult{Status: game.VoteAccepted}, mafiaVote(strconv.Itoa(int(killed.ID))))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	nightLog := g.Night()
+	cancel()
+	<-done
+
+	active := g.GetActivePlayers()
+	alive := lo.Without(active.GetIDs(), mafia.ID, killed.ID)
+	result := mafiaVote(strconv.Itoa(int(alive[0])))
+	assert.Equal(t, game.VoteTooLate, result.Status)
+	assert.ErrorIs(t, result.Err, game.VoteTooLateErr)
+	assert.Contains(t, nightLog.Dead, killed.ID)
+}
+
+func TestNightTeamVotePolicies(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name   string
+		policy game.TeamVotePolicy
+		// The second Mafia votes.
+		isSecondVotes bool
+		// The Don votes in the turn of the Mafia.
+		isDonVotes bool
+		isDecided  bool
+	}{
+		{
+			name:          "majority with the Don",
+			policy:        game.TeamVotePolicy{Kind: game.MajorityTeamVote, Leader: roles.Don},
+			isSecondVotes: true,
+			isDonVotes:    true,
+			isDecided:     true,
+		},
+		{
+			// The Don breaks the tie, his decision replaces the empty vote of the second Mafia.
+			name:       "tie broken by the Don",
+			policy:     game.TeamVotePolicy{Kind: game.MajorityTeamVote, Leader: roles.Don},
+			isDonVotes: true,
+			isDecided:  true,
+		},
+		{
+			name:          "unanimous",
+			policy:        game.TeamVotePolicy{Kind: game.UnanimousTeamVote},
+			isSecondVotes: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g, err := initHelper(config.GetConfigByPlayersCountAndIndex(10, 0),
+				game.TeamVotePolicyOpt(roles.Mafia, tc.policy))
+			require.NoError(t, err)
+			mappedPlayers := playersHelper(g.GetActivePlayers())
+			mafia, don := mappedPlayers[roles.Mafia], mappedPlayers[roles.Don][0]
+			first, second := mappedPlayers[roles.Peaceful][0], mappedPlayers[roles.Peaceful][1]
+			nightVote := func(voter, vote *player.Player) {
+				result := g.SubmitNightVote(game.NewVoteProvider(
+					strconv.Itoa(int(voter.ID)), strconv.Itoa(int(vote.ID)), false, false))
+				assert.Equal(t, game.VoteResult{Status: game.VoteAccepted}, result)
+			}
+
+			clock := fakeClock(g)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				for {
+					select {
+					case <-g.GetErrorChan():
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for {
+					select {
+					case s := <-g.GetInfoChan():
+						votedRole := signalHandler(s)
+						if votedRole == nil {
+							continue
+						}
+						if votedRole == roles.Mafia {
+							nightVote(mafia[0], first)
+							if tc.isSecondVotes {
+								nightVote(mafia[1], second)
+							}
+							if tc.isDonVotes {
+								nightVote(don, second)
+								if tc.isSecondVotes {
+									continue
+								}
+							}
+						}
+						_ = clock.BlockUntilContext(ctx, 1)
+						clock.AdvanceToNext()
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			nightLog := g.Night()
+			cancel()
+			<-done
+
+			// The votes of the Mafia are kept, one per night.
+			assert.Equal(t, []player.IDType{first.ID}, nightLog.NightVotes[mafia[0].ID])
+			active := g.GetActivePlayers()
+			for _, p := range mafia {
+				assert.Len(t, active.GetByIDType(p.ID).Votes, 1)
+			}
+			assert.Equal(t, []player.IDType{second.ID}, nightLog.NightVotes[mafia[1].ID])
+			if !tc.isDecided {
+				assert.NotContains(t, nightLog.Decisions, roles.Mafia.Name)
+				assert.Empty(t, nightLog.Dead)
+				return
+			}
+			assert.Equal(t, []player.IDType{second.ID}, nightLog.Decisions[roles.Mafia.Name])
+			assert.Equal(t, []player.IDType{second.ID}, nightLog.Dead)
+		})
+	}
+}
+
+func TestNightTargetRules(t *testing.T) {
+	t.Parallel()
+	cfg := config.GetConfigByPlayersCountAndIndex(10, 0)
+
+	t.Run("Mafia can not target a teammate", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.TargetRulesOpt(roles.Mafia, roles.TargetRules{NoTeammateTarget: true}))
+		require.NoError(t, err)
+		mappedPlayers := playersHelper(g.GetActivePlayers())
+		err = takeANight(g, votesCfg{
+			roles.Mafia: {
+				role:  roles.Mafia,
+				votes: []player.IDType{mappedPlayers[roles.Don][0].ID},
+			},
+		})
+		assert.ErrorIs(t, err, game.TeammateTargetErr)
+	})
+	t.Run("Don votes by the rules of the Mafia in its turn", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg,
+			game.TeamVotePolicyOpt(roles.Mafia, game.TeamVotePolicy{Kind: game.MajorityTeamVote, Leader: roles.Don}),
+			game.TargetRulesOpt(roles.Mafia, roles.TargetRules{NoTeammateTarget: true}))
+		require.NoError(t, err)
+		mappedPlayers := playersHelper(g.GetActivePlayers())
+		err = takeANight(g, votesCfg{
+			roles.Mafia: {
+				role:  roles.Don,
+				votes: []player.IDType{mappedPlayers[roles.Mafia][0].ID},
+			},
+		})
+		assert.ErrorIs(t, err, game.TeammateTargetErr)
+	})
+	t.Run("Detective can not check himself", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.VoteForYourselfOpt(true),
+			game.TargetRulesOpt(roles.Detective, roles.TargetRules{NoSelfTarget: true}))
+		require.NoError(t, err)
+		mappedPlayers := playersHelper(g.GetActivePlayers())
+		err = takeANight(g, votesCfg{
+			roles.Detective: {
+				role:  roles.Detective,
+				votes: []player.IDType{mappedPlayers[roles.Detective][0].ID, mappedPlayers[roles.Peaceful][0].ID},
+			},
+		})
+		assert.ErrorIs(t, err, game.CannotVoteToYourselfErr)
+	})
+	t.Run("Doctor self-heals once", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.TargetRulesOpt(roles.Doctor, roles.TargetRules{SelfTargetLimit: 1}))
+		require.NoError(t, err)
+		mappedPlayers := playersHelper(g.GetActivePlayers())
+		vCfg := votesCfg{
+			roles.Doctor: {
+				role:  roles.Doctor,
+				votes: []player.IDType{mappedPlayers[roles.Doctor][0].ID},
+			},
+		}
+		require.NoError(t, takeANight(g, vCfg))
+		g.AffectNight(g.NewNightLog())
+		assert.ErrorIs(t, takeANight(g, vCfg), game.SelfTargetLimitErr)
+	})
+	t.Run("Doctor can not heal the same player two nights in a row", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.VotePingOpt(0),
+			game.TargetRulesOpt(roles.Doctor, roles.TargetRules{NoRepeatTarget: true}))
+		require.NoError(t, err)
+		mappedPlayers := playersHelper(g.GetActivePlayers())
+		vCfg := votesCfg{
+			roles.Doctor: {
+				role:  roles.Doctor,
+				votes: []player.IDType{mappedPlayers[roles.Peaceful][0].ID},
+			},
+		}
+		require.NoError(t, takeANight(g, vCfg))
+		g.AffectNight(g.NewNightLog())
+		assert.ErrorIs(t, takeANight(g, vCfg), game.RepeatTargetErr)
+	})
+}
+
+func TestNightLegalTargets(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(10, 0),
+		game.TargetRulesOpt(roles.Mafia, roles.TargetRules{NoTeammateTarget: true}),
+		game.TargetRulesOpt(roles.Detective, roles.TargetRules{NoSelfTarget: true}))
+	require.NoError(t, err)
+	mappedPlayers := playersHelper(g.GetActivePlayers())
+	mafia, detective := mappedPlayers[roles.Mafia][0], mappedPlayers[roles.Detective][0]
+	peaceful := mappedPlayers[roles.Peaceful][0]
+	ids := activeIDs(g)
+	legalTargets := func(p *player.Player) ([]player.IDType, error) {
+		return g.GetLegalTargets(strconv.Itoa(int(p.ID)), false)
+	}
+
+	clock := fakeClock(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for {
+			select {
+			case <-g.GetErrorChan():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case s := <-g.GetInfoChan():
+				votedRole := signalHandler(s)
+				if votedRole == nil {
+					continue
+				}
+				switch votedRole {
+				case roles.Mafia:
+					// Not the teammates.
+					targets, targetsErr := legalTargets(mafia)
+					assert.NoError(t, targetsErr)
+					teammates := append(mappedPlayers[roles.Mafia], mappedPlayers[roles.Don]...)
+					assert.Equal(t, lo.Without(ids, lo.Map(teammates, func(p *player.Player, _ int) player.IDType {
+						return p.ID
+					})...), targets)
+
+					_, targetsErr = legalTargets(peaceful)
+					assert.ErrorIs(t, targetsErr, game.IncorrectVoteTimeErr)
+				case roles.Detective:
+					targets, targetsErr := legalTargets(detective)
+					assert.NoError(t, targetsErr)
+					assert.Equal(t, lo.Without(ids, detective.ID), targets)
+				}
+				_ = clock.BlockUntilContext(ctx, 1)
+				clock.AdvanceToNext()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	g.Night()
+	cancel()
+	<-done
+
+	_, err = legalTargets(mafia)
+	assert.ErrorIs(t, err, game.IncorrectVoteTimeErr)
+}
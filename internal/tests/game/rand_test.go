@@ -0,0 +1,42 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandSeed_SameDeal(t *testing.T) {
+	t.Parallel()
+	const seed = 20240101
+	cfg := config.GetConfigByPlayersCountAndIndex(14, 0)
+
+	deal := func(g *game.Game) map[player.IDType]*roles.Role {
+		dealt := make(map[player.IDType]*roles.Role)
+		for id, p := range g.GetActivePlayers() {
+			dealt[id] = p.Role
+		}
+		return dealt
+	}
+
+	g1, err := initHelper(cfg, game.RandSeedOpt(seed))
+	require.NoError(t, err)
+	g2, err := initHelper(cfg, game.RandSeedOpt(seed))
+	require.NoError(t, err)
+	assert.Equal(t, deal(g1), deal(g2))
+
+	clone, err := g1.GetDeepClone()
+	require.NoError(t, err)
+	assert.Equal(t, int64(seed), clone.Seed)
+
+	// Reproduce from the clone.
+	g3, err := initHelper(cfg, game.RandSeedOpt(clone.Seed))
+	require.NoError(t, err)
+	assert.Equal(t, deal(g1), deal(g3))
+}
@@ -0,0 +1,191 @@
+package game
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/https-whoyan/MafiaCore/channel"
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+	timePack "github.com/https-whoyan/MafiaCore/time"
+
+	"github.com/https-whoyan/MafiaCore/internal/tests/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nightSnapshotStorage keeps only the snapshots after the nights.
+type nightSnapshotStorage struct {
+	snapshots chan game.DeepCloneGame
+}
+
+func (s nightSnapshotStorage) InitNewGame(context.Context, game.DeepCloneGame) error { return nil }
+func (s nightSnapshotStorage) SaveNightLog(_ context.Context, g game.DeepCloneGame, _ game.NightLog) error {
+	select {
+	case s.snapshots <- g:
+	default:
+	}
+	return nil
+}
+func (s nightSnapshotStorage) SaveDayLog(context.Context, game.DeepCloneGame, game.DayLog) error {
+	return nil
+}
+func (s nightSnapshotStorage) SaveFinishLog(context.Context, game.DeepCloneGame, game.FinishLog) error {
+	return nil
+}
+func (s nightSnapshotStorage) NameAGame(context.Context, game.DeepCloneGame, string) error {
+	return nil
+}
+
+func restoreHelper(ctx context.Context, clone game.DeepCloneGame) (*game.Game, error) {
+	var roleChannels []channel.RoleChannel
+	for _, roleChannel := range models.NewTestChannels() {
+		roleChannels = append(roleChannels, roleChannel)
+	}
+	return game.Restore(ctx, clone, models.NewTestMainChannels(), roleChannels,
+		game.FMTerOpt(models.TestFMTInstance),
+		game.RenamePrOpt(models.TestRenameUserProviderInstance),
+		game.ClockOpt(timePack.NewFakeClock(time.Now())),
+	)
+}
+
+func drainErrors(g *game.Game) {
+	go func() {
+		for range g.GetErrorChan() {
+		}
+	}()
+}
+
+func TestRestore_AfterNight(t *testing.T) {
+	t.Parallel()
+	storage := nightSnapshotStorage{snapshots: make(chan game.DeepCloneGame, 1)}
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(7, 3), game.StorageOpt(storage))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go driveClock(ctx, fakeClock(g))
+	_, infoCh := g.Run(ctx)
+	drainErrors(g)
+	go func() {
+		for range infoCh {
+		}
+	}()
+	snapshot := <-storage.snapshots
+	cancel()
+
+	restoredCtx, restoredCancel := context.WithCancel(context.Background())
+	defer restoredCancel()
+	restored, err := restoreHelper(restoredCtx, snapshot)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, restored.GetNightsCount())
+	restoredActive := restored.GetActivePlayers()
+	assert.Equal(t, snapshot.Active.GetIDs(), restoredActive.GetIDs())
+	for _, p := range restoredActive {
+		mappedRole := restored.GetConfig().RolesMp[p.Role.Name]
+		require.NotNil(t, mappedRole)
+		// The roles are the same pointers, as in the config.
+		assert.Same(t, mappedRole.Role, p.Role)
+	}
+	events := restored.GetEvents()
+	require.NotEmpty(t, events)
+	assert.Equal(t, game.GameRestoredEvent, events[0].EventType)
+
+	// The game continues from the day.
+	go driveClock(restoredCtx, fakeClock(restored))
+	_, restoredInfoCh := restored.Run(restoredCtx)
+	drainErrors(restored)
+	for s := range restoredInfoCh {
+		if switchState, ok := s.Info.(game.SwitchStateInfo); ok {
+			assert.Equal(t, game.State(game.DayState), switchState.NewState)
+			assert.Equal(t, 1, switchState.DayCounter)
+			break
+		}
+	}
+	go func() {
+		for range restoredInfoCh {
+		}
+	}()
+}
+
+func TestRestore_FinishedGame(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(5, 1))
+	require.NoError(t, err)
+	clone, err := g.GetDeepClone()
+	require.NoError(t, err)
+	clone.State = game.FinishState
+
+	_, err = restoreHelper(context.Background(), clone)
+	assert.ErrorIs(t, err, game.NotRestorableStateErr)
+}
+
+func TestRestore_InTheNight(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(7, 3))
+	require.NoError(t, err)
+	require.NoError(t, takeANight(g, votesCfg{}))
+	g.AffectNight(g.NewNightLog())
+	votesAfterNight := make(map[player.IDType][]player.IDType)
+	for _, p := range g.GetActivePlayers() {
+		votesAfterNight[p.ID] = slices.Clone(p.Votes)
+	}
+
+	// The snapshot is made in the turn of the Doctor, after the kill of the Mafia.
+	mappedPlayers := playersHelper(g.GetActivePlayers())
+	mafiaVotes := votesCfg{
+		roles.Mafia: {
+			role:  roles.Mafia,
+			votes: []player.IDType{mappedPlayers[roles.Peaceful][0].ID},
+		},
+	}
+	var snapshot game.DeepCloneGame
+	clock := fakeClock(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	drainErrors(g)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case s := <-g.GetInfoChan():
+				votedRole := signalHandler(s)
+				if votedRole == nil {
+					continue
+				}
+				if votedRole == roles.Doctor {
+					var snapshotErr error
+					snapshot, snapshotErr = g.GetDeepClone()
+					assert.NoError(t, snapshotErr)
+				}
+				if !vote(g, mafiaVotes, votedRole, func(error) {}) {
+					_ = clock.BlockUntilContext(ctx, 1)
+					clock.AdvanceToNext()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	g.Night()
+	cancel()
+	<-done
+	require.Equal(t, game.State(game.NightState), snapshot.State)
+	// The nights are counted by Run, the second night is not over.
+	snapshot.NightCounter = len(snapshot.NightLogs) + 1
+
+	restored, err := restoreHelper(context.Background(), snapshot)
+	require.NoError(t, err)
+	// The second night is played again.
+	assert.Equal(t, len(snapshot.NightLogs), restored.GetNightsCount())
+	for _, p := range restored.GetActivePlayers() {
+		assert.Equal(t, votesAfterNight[p.ID], p.Votes)
+		assert.Equal(t, player.Alive, p.LifeStatus)
+	}
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignals_Night(t *testing.T) {
+	t.Parallel()
+	g, err := initHelper(config.GetConfigByPlayersCountAndIndex(5, 1))
+	require.NoError(t, err)
+	mappedPlayers := playersHelper(g.GetActivePlayers())
+	killed, mafia := mappedPlayers[roles.Peaceful][0], mappedPlayers[roles.Mafia][0]
+	votes := votesCfg{
+		roles.Mafia: {
+			role:  roles.Mafia,
+			votes: []player.IDType{killed.ID},
+		},
+	}
+
+	clock := fakeClock(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for {
+			select {
+			case <-g.GetErrorChan():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var infos []any
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for s := range g.GetInfoChan() {
+			infos = append(infos, s.Info)
+			if _, ok := s.Info.(game.NightResultInfo); ok {
+				return
+			}
+			if votedRole := signalHandler(s); votedRole != nil && !vote(g, votes, votedRole, func(error) {}) {
+				_ = clock.BlockUntilContext(ctx, 1)
+				clock.AdvanceToNext()
+			}
+		}
+	}()
+	g.AffectNight(g.Night())
+	<-done
+
+	indexOf := func(info any) int {
+		for i, got := range infos {
+			if assert.ObjectsAreEqual(info, got) {
+				return i
+			}
+		}
+		return -1
+	}
+	// The vote does not wait for the timer of the role.
+	votingSwitched := indexOf(game.SwitchVotingRoleInfo{CurrVotingRole: roles.Mafia})
+	timerStarted := indexOf(game.TimerStartedInfo{
+		State:      game.NightState,
+		VotingRole: roles.Mafia,
+		Deadline:   g.GetTimings().GetVotingDeadline(roles.Mafia.Name),
+	})
+	voteCast := indexOf(game.VoteCastInfo{
+		State:   game.NightState,
+		VoterID: mafia.ID,
+		Votes:   []player.IDType{killed.ID},
+	})
+	died := indexOf(game.PlayerDiedInfo{
+		PlayerID:  killed.ID,
+		Reason:    player.KilledAtNight,
+		LivedDays: g.GetNightsCount(),
+	})
+	require.NotEqual(t, -1, votingSwitched)
+	require.NotEqual(t, -1, timerStarted)
+	require.NotEqual(t, -1, voteCast)
+	require.NotEqual(t, -1, died)
+	assert.Less(t, votingSwitched, timerStarted)
+	assert.Less(t, votingSwitched, voteCast)
+	assert.Less(t, voteCast, died)
+
+	result, ok := infos[len(infos)-1].(game.NightResultInfo)
+	require.True(t, ok)
+	assert.Equal(t, []player.IDType{killed.ID}, result.Log.Dead)
+}
@@ -3,24 +3,27 @@ package game
 import (
 	"context"
 	"strconv"
-	"sync"
+	"time"
 
 	"github.com/https-whoyan/MafiaCore/config"
 	"github.com/https-whoyan/MafiaCore/game"
 	"github.com/https-whoyan/MafiaCore/player"
 	"github.com/https-whoyan/MafiaCore/roles"
+	timePack "github.com/https-whoyan/MafiaCore/time"
 
 	"github.com/https-whoyan/MafiaCore/internal/tests/models"
 )
 
-func initHelper(cfg *config.RolesConfig) (*game.Game, error) {
+func initHelper(cfg *config.RolesConfig, extraOpts ...game.Option) (*game.Game, error) {
 	var internalErr error
 
 	opts := []game.Option{
 		game.FMTerOpt(models.TestFMTInstance),
 		game.RenamePrOpt(models.TestRenameUserProviderInstance),
+		game.ClockOpt(timePack.NewFakeClock(time.Now())),
 	}
-	g := game.GetNewGame(models.TestingGuildID, opts...)
+	opts = append(opts, extraOpts...)
+	g := game.GetNewGame(context.Background(), models.TestingGuildID, opts...)
 
 	allRoleChannels := models.NewTestChannels()
 	mainChannel := models.NewTestMainChannels()
@@ -65,13 +68,29 @@ type voteCfg struct {
 	votes []player.IDType
 }
 
+// fakeClock returns the clock of the game, created by initHelper.
+func fakeClock(g *game.Game) *timePack.FakeClock {
+	return g.GetClock().(*timePack.FakeClock)
+}
+
+// driveClock fires every timer of the game as soon as the game waits for it, until ctx is done.
+func driveClock(ctx context.Context, clock *timePack.FakeClock) {
+	for {
+		if err := clock.BlockUntilContext(ctx, 1); err != nil {
+			return
+		}
+		clock.AdvanceToNext()
+	}
+}
+
+// takeANight plays one night of the game (without affecting it), with the votes of c.
+//
+// If the role has no vote in c (or all players with the role are muted), the role's timer is fired.
 func takeANight(g *game.Game, c votesCfg) error {
-	var (
-		errChannel  <-chan game.ErrSignal
-		infoChannel <-chan game.InfoSignal
-	)
-	wg := sync.WaitGroup{}
-	wg.Add(3)
+	clock := fakeClock(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	var (
 		err      error
 		standErr = func(fnErr error) {
@@ -81,43 +100,75 @@ func takeANight(g *game.Game, c votesCfg) error {
 		}
 	)
 	go func() {
-		defer wg.Done()
-		errChannel, infoChannel = g.Run(context.Background())
-	}()
-	go func() {
-		defer wg.Done()
-		active := g.GetActivePlayers()
-		for s := range infoChannel {
-			if g.GetState() == game.DayState {
+		for {
+			select {
+			case <-g.GetErrorChan():
+			case <-ctx.Done():
 				return
 			}
-			votedRole := signalHandler(s)
-			if votedRole == nil {
-				continue
-			}
-			if votedRole.IsTwoVotes {
-				vP := c[votedRole].toTwoVotePr(&active)
-
-				standErr(g.SetNightTwoVote(vP))
-				continue
-			}
-			vP := c[votedRole].toVotePr(&active)
-			standErr(g.SetNightVote(vP))
 		}
 	}()
+
+	done := make(chan struct{})
 	go func() {
-		defer wg.Done()
-		for range errChannel {
+		defer close(done)
+		for {
+			select {
+			case s := <-g.GetInfoChan():
+				votedRole := signalHandler(s)
+				if votedRole == nil {
+					continue
+				}
+				if !vote(g, c, votedRole, standErr) {
+					_ = clock.BlockUntilContext(ctx, 1)
+					clock.AdvanceToNext()
+				}
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
-	wg.Wait()
+
+	g.Night()
+	cancel()
+	<-done
 	return err
 }
 
+// vote sets the vote of votedRole from c. Returns false, if the vote was not accepted.
+func vote(g *game.Game, c votesCfg, votedRole *roles.Role, standErr func(error)) bool {
+	vCfg, ok := c[votedRole]
+	if !ok {
+		return false
+	}
+	active := g.GetActivePlayers()
+	canVote := false
+	for _, p := range *active.SearchAllPlayersWithRole(votedRole) {
+		if p.InteractionStatus != player.Muted {
+			canVote = true
+		}
+	}
+	if !canVote {
+		return false
+	}
+
+	var err error
+	if votedRole.IsTwoVotes {
+		err = g.SetNightTwoVote(vCfg.toTwoVotePr(&active))
+	} else {
+		err = g.SetNightVote(vCfg.toVotePr(&active))
+	}
+	standErr(err)
+	return err == nil
+}
+
 func (v voteCfg) toTwoVotePr(players *player.Players) *game.NightTwoVotesProvider {
 	votedPlayers := *(players.SearchAllPlayersWithRole(v.role))
 	var votedPlayer = &player.Player{}
 	for _, p := range votedPlayers {
+		if p.InteractionStatus == player.Muted {
+			continue
+		}
 		votedPlayer = p
 	}
 	return &game.NightTwoVotesProvider{
@@ -133,6 +184,9 @@ func (v voteCfg) toVotePr(players *player.Players) *game.OneVoteProvider {
 	votedPlayers := *(players.SearchAllPlayersWithRole(v.role))
 	var votedPlayer = &player.Player{}
 	for _, p := range votedPlayers {
+		if p.InteractionStatus == player.Muted {
+			continue
+		}
 		votedPlayer = p
 	}
 	return &game.OneVoteProvider{
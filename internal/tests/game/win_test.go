@@ -0,0 +1,99 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/registry"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWinConditions_HouseRules(t *testing.T) {
+	t.Parallel()
+	cfg := &config.RolesConfig{
+		PlayersCount: 2,
+		RolesMp: map[string]*config.RoleConfig{
+			roles.Mafia.Name:  {Role: roles.Mafia, Count: 1},
+			roles.Maniac.Name: {Role: roles.Maniac, Count: 1},
+		},
+	}
+
+	t.Run("Mafia wins 1v1 by default", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg)
+		require.NoError(t, err)
+		winner := g.UnderstandWinnerTeam()
+		require.NotNil(t, winner)
+		assert.Equal(t, roles.MafiaTeam, *winner)
+	})
+	t.Run("Maniac wins 1v1", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.TeamWinConditionOpt(roles.ManiacTeam, registry.TeamParity(roles.ManiacTeam)))
+		require.NoError(t, err)
+		winner := g.UnderstandWinnerTeam()
+		require.NotNil(t, winner)
+		assert.Equal(t, roles.ManiacTeam, *winner)
+	})
+	t.Run("Nobody can win", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg,
+			game.TeamWinConditionOpt(roles.MafiaTeam, nil),
+			game.TeamWinConditionOpt(roles.ManiacTeam, nil),
+		)
+		require.NoError(t, err)
+		assert.Nil(t, g.UnderstandWinnerTeam())
+	})
+}
+
+func TestWinConditions_SoloHouseRules(t *testing.T) {
+	t.Parallel()
+	alwaysWon := registry.WinConditionFunc(func(_ registry.Env) bool { return true })
+
+	t.Run("Maniac wins 1v1 on its own", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.RolesConfig{
+			PlayersCount: 2,
+			RolesMp: map[string]*config.RoleConfig{
+				roles.Mafia.Name:  {Role: roles.Mafia, Count: 1},
+				roles.Maniac.Name: {Role: roles.Maniac, Count: 1},
+			},
+		}
+		g, err := initHelper(cfg, game.SoloWinConditionOpt(roles.Maniac, registry.TeamParity(roles.ManiacTeam)))
+		require.NoError(t, err)
+		winner := g.UnderstandSoloWinner()
+		require.Equal(t, roles.Maniac, winner)
+		finishLog := g.NewSoloFinishLog(winner)
+		assert.Equal(t, roles.Maniac.Name, finishLog.SoloWinner)
+		assert.False(t, finishLog.IsFool)
+		assert.Nil(t, finishLog.WinnerTeam)
+	})
+
+	cfg := &config.RolesConfig{
+		PlayersCount: 4,
+		RolesMp: map[string]*config.RoleConfig{
+			roles.Mafia.Name:    {Role: roles.Mafia, Count: 1},
+			roles.Fool.Name:     {Role: roles.Fool, Count: 1},
+			roles.Peaceful.Name: {Role: roles.Peaceful, Count: 2},
+		},
+	}
+	t.Run("Fool condition is replaced", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.SoloWinConditionOpt(roles.Fool, alwaysWon))
+		require.NoError(t, err)
+		winner := g.UnderstandSoloWinner()
+		require.Equal(t, roles.Fool, winner)
+		finishLog := g.NewSoloFinishLog(winner)
+		assert.Equal(t, roles.Fool.Name, finishLog.SoloWinner)
+		assert.True(t, finishLog.IsFool)
+	})
+	t.Run("Fool plays only for his team", func(t *testing.T) {
+		t.Parallel()
+		g, err := initHelper(cfg, game.SoloWinConditionOpt(roles.Fool, nil))
+		require.NoError(t, err)
+		assert.Nil(t, g.UnderstandSoloWinner())
+	})
+}
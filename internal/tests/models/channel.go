@@ -44,6 +44,17 @@ func (c *TestRoleChannel) GetRole() *roles.Role { return c.Role }
 
 type TestMainChannel struct {
 	TestChannel
+	// Speakers presents the only users, who may write now (nil means everyone).
+	Speakers []string
+}
+
+func (c *TestMainChannel) AllowOnlySpeakers(serverUserIDs ...string) error {
+	c.Speakers = serverUserIDs
+	return nil
+}
+func (c *TestMainChannel) AllowAllSpeakers() error {
+	c.Speakers = nil
+	return nil
 }
 
 func NewTestMainChannel(channelIID string) *TestMainChannel {
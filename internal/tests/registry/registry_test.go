@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/registry"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var bodyguard = &roles.Role{
+	Name:             "Bodyguard",
+	Team:             roles.PeacefulTeam,
+	NightVoteOrder:   8,
+	CalculationOrder: 5,
+}
+
+type bodyguardBehavior struct{ registry.BaseBehavior }
+
+func (bodyguardBehavior) NightAction(env registry.Env, p *player.Player) *registry.InteractionMessage {
+	defended, isEmpty := registry.LastVoteTarget(env, p)
+	if isEmpty {
+		return nil
+	}
+	defended.LifeStatus = player.Alive
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("Built-in roles are registered", func(t *testing.T) {
+		for _, role := range roles.GetAllSortedRoles() {
+			assert.True(t, registry.IsRegistered(role), role.Name)
+		}
+		assert.Contains(t, registry.GetWinConditions(), roles.Fool)
+	})
+	t.Run("Custom role", func(t *testing.T) {
+		require.NoError(t, registry.Register(bodyguard, bodyguardBehavior{}))
+
+		mappedRole, ok := roles.GetRoleByName(bodyguard.Name)
+		assert.True(t, ok)
+		assert.Equal(t, bodyguard, mappedRole)
+		assert.Equal(t, bodyguardBehavior{}, registry.GetBehavior(bodyguard))
+		assert.Contains(t, roles.GetAllNightInteractionRolesNames(), bodyguard.Name)
+	})
+	t.Run("Invalid registrations", func(t *testing.T) {
+		assert.ErrorIs(t, registry.Register(nil, bodyguardBehavior{}), registry.NilRoleErr)
+		assert.ErrorIs(t, registry.Register(&roles.Role{}, bodyguardBehavior{}), registry.EmptyRoleNameErr)
+		assert.ErrorIs(t, registry.Register(bodyguard, nil), registry.NilBehaviorErr)
+
+		fakeMafia := &roles.Role{Name: roles.Mafia.Name}
+		assert.ErrorIs(t, registry.Register(fakeMafia, bodyguardBehavior{}), registry.RoleNameAlreadyTakenErr)
+	})
+	t.Run("Register while the roles are read", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				role := &roles.Role{Name: "Runtime " + strconv.Itoa(i), Team: roles.PeacefulTeam, NightVoteOrder: -1}
+				assert.NoError(t, registry.Register(role, registry.BaseBehavior{}))
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = roles.GetRoleByName(roles.Mafia.Name)
+				_ = roles.GetAllSortedRoles()
+				_ = roles.GetAllNightInteractionRolesNames()
+			}()
+		}
+		wg.Wait()
+		_, ok := roles.GetRoleByName("Runtime 9")
+		assert.True(t, ok)
+	})
+	t.Run("Unknown role has no actions", func(t *testing.T) {
+		assert.Equal(t, registry.BaseBehavior{}, registry.GetBehavior(&roles.Role{Name: "Unknown"}))
+	})
+}
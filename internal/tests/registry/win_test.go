@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/https-whoyan/MafiaCore/channel"
+	"github.com/https-whoyan/MafiaCore/fmt"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/registry"
+	"github.com/https-whoyan/MafiaCore/roles"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// activeEnv presents only the active players.
+type activeEnv struct {
+	active player.Players
+}
+
+func newActiveEnv(activeRoles ...*roles.Role) activeEnv {
+	active := make(player.Players)
+	for i, role := range activeRoles {
+		id := player.IDType(i + 1)
+		active[id] = &player.Player{ID: id, Role: role}
+	}
+	return activeEnv{active: active}
+}
+
+func (e activeEnv) Active() *player.Players                     { return &e.active }
+func (e activeEnv) Dead() *player.DeadPlayers                   { return &player.DeadPlayers{} }
+func (e activeEnv) FMT() fmt.FmtInterface                       { return nil }
+func (e activeEnv) RoleChannel(*roles.Role) channel.RoleChannel { return nil }
+func (e activeEnv) NightCounter() int                           { return 1 }
+
+func winners(env registry.Env) []roles.Team {
+	var teams []roles.Team
+	for _, team := range []roles.Team{roles.PeacefulTeam, roles.MafiaTeam, roles.ManiacTeam} {
+		if registry.GetTeamWinCondition(team).IsWon(env) {
+			teams = append(teams, team)
+		}
+	}
+	return teams
+}
+
+func TestTeamWinConditions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		env     activeEnv
+		winners []roles.Team
+	}{
+		{
+			name:    "Only peaceful",
+			env:     newActiveEnv(roles.Peaceful, roles.Doctor),
+			winners: []roles.Team{roles.PeacefulTeam},
+		},
+		{
+			name: "Game goes on",
+			env:  newActiveEnv(roles.Peaceful, roles.Doctor, roles.Mafia),
+		},
+		{
+			name:    "Mafia parity",
+			env:     newActiveEnv(roles.Peaceful, roles.Mafia),
+			winners: []roles.Team{roles.MafiaTeam},
+		},
+		{
+			name:    "Three teams, mafia has the majority",
+			env:     newActiveEnv(roles.Peaceful, roles.Maniac, roles.Mafia, roles.Don, roles.Mafia),
+			winners: []roles.Team{roles.MafiaTeam},
+		},
+		{
+			name: "Three teams, no majority",
+			env:  newActiveEnv(roles.Peaceful, roles.Peaceful, roles.Maniac, roles.Mafia),
+		},
+		{
+			name:    "Maniac against mafia, 1v1",
+			env:     newActiveEnv(roles.Maniac, roles.Mafia),
+			winners: []roles.Team{roles.MafiaTeam, roles.ManiacTeam},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.winners, winners(testCase.env))
+		})
+	}
+}
+
+func TestWinConditionHelpers(t *testing.T) {
+	// Mafia wins at parity only if no Doctor alive.
+	houseRule := registry.AnyOf(
+		registry.LastTeamStanding(roles.MafiaTeam),
+		registry.AllOf(registry.TeamParity(roles.MafiaTeam), registry.RoleIsOut(roles.Doctor)),
+	)
+	assert.False(t, houseRule.IsWon(newActiveEnv(roles.Doctor, roles.Mafia)))
+	assert.True(t, houseRule.IsWon(newActiveEnv(roles.Peaceful, roles.Mafia)))
+	assert.True(t, houseRule.IsWon(newActiveEnv(roles.Mafia)))
+	assert.False(t, registry.AllOf().IsWon(newActiveEnv(roles.Mafia)))
+}
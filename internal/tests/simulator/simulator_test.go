@@ -0,0 +1,45 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/roles"
+	"github.com/https-whoyan/MafiaCore/simulator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulator_Run(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cfg := config.GetConfigByPlayersCountAndIndex(7, 3)
+	s, err := simulator.New(cfg, simulator.GamesOpt(50), simulator.SeedOpt(1))
+	require.NoError(t, err)
+	report, err := s.Run(ctx)
+	require.NoError(t, err)
+	t.Log(report)
+
+	assert.Equal(t, 50, report.Games)
+	total := report.Unfinished
+	for _, wins := range report.TeamWins {
+		total += wins
+	}
+	for _, wins := range report.SoloWins {
+		total += wins
+	}
+	assert.Equal(t, report.Games, total)
+	assert.Greater(t, report.AverageNights, 0.0)
+	assert.Greater(t, report.TeamWinRate(roles.MafiaTeam)+report.TeamWinRate(roles.PeacefulTeam), 0.0)
+
+	// The same seed gives the same results.
+	again, err := s.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, report.TeamWins, again.TeamWins)
+	assert.Equal(t, report.AverageNights, again.AverageNights)
+}
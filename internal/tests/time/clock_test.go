@@ -0,0 +1,84 @@
+package time
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	timePack "github.com/https-whoyan/MafiaCore/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func isFired(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Advance fires only expired timers", func(t *testing.T) {
+		t.Parallel()
+		clock := timePack.NewFakeClock(start)
+		short := clock.NewTimer(time.Second)
+		long := clock.NewTimer(time.Minute)
+		assert.Equal(t, 2, clock.Waiters())
+
+		clock.Advance(30 * time.Second)
+		assert.True(t, isFired(short.C()))
+		assert.False(t, isFired(long.C()))
+		assert.Equal(t, start.Add(30*time.Second), clock.Now())
+		assert.Equal(t, 1, clock.Waiters())
+	})
+	t.Run("AdvanceToNext", func(t *testing.T) {
+		t.Parallel()
+		clock := timePack.NewFakeClock(start)
+		_, ok := clock.AdvanceToNext()
+		assert.False(t, ok)
+
+		timer := clock.NewTimer(40 * time.Second)
+		d, ok := clock.AdvanceToNext()
+		assert.True(t, ok)
+		assert.Equal(t, 40*time.Second, d)
+		assert.True(t, isFired(timer.C()))
+	})
+	t.Run("Stop and Reset", func(t *testing.T) {
+		t.Parallel()
+		clock := timePack.NewFakeClock(start)
+		timer := clock.NewTimer(time.Second)
+		assert.True(t, timer.Stop())
+		assert.False(t, timer.Stop())
+		clock.Advance(time.Hour)
+		assert.False(t, isFired(timer.C()))
+
+		assert.False(t, timer.Reset(time.Second))
+		clock.Advance(time.Second)
+		assert.True(t, isFired(timer.C()))
+	})
+	t.Run("Sleep with BlockUntil", func(t *testing.T) {
+		t.Parallel()
+		clock := timePack.NewFakeClock(start)
+		done := make(chan struct{})
+		go func() {
+			clock.Sleep(10 * time.Second)
+			close(done)
+		}()
+		clock.BlockUntil(1)
+		clock.Advance(10 * time.Second)
+		<-done
+	})
+	t.Run("BlockUntilContext is cancelable", func(t *testing.T) {
+		t.Parallel()
+		clock := timePack.NewFakeClock(start)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, clock.BlockUntilContext(ctx, 1), context.Canceled)
+	})
+}
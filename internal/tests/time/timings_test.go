@@ -0,0 +1,78 @@
+package time
+
+import (
+	"testing"
+	"time"
+
+	timePack "github.com/https-whoyan/MafiaCore/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withoutFormula returns the timings without DayDeadline, because the functions can not be compared.
+func withoutFormula(t *testing.T, timings timePack.Timings) timePack.Timings {
+	t.Helper()
+	require.NotNil(t, timings.DayDeadline)
+	timings.DayDeadline = nil
+	return timings
+}
+
+func TestTimings_WithDefaults(t *testing.T) {
+	t.Parallel()
+	defaults := timePack.DefaultTimings()
+
+	t.Run("Zero timings are the defaults", func(t *testing.T) {
+		t.Parallel()
+		timings := timePack.Timings{}.WithDefaults()
+		assert.Equal(t, withoutFormula(t, defaults), withoutFormula(t, timings))
+		assert.Equal(t, timePack.CalculateDayDeadline(3, 2, 10), timings.DayDeadline(3, 2, 10))
+	})
+	t.Run("Partial overrides are kept", func(t *testing.T) {
+		t.Parallel()
+		formula := func(_, _, _ int) time.Duration { return time.Minute }
+		timings := timePack.Timings{
+			VotingDeadline:  10 * time.Second,
+			DefenseDeadline: -time.Second,
+			LobbyCountdown:  5 * time.Second,
+			DayDeadline:     formula,
+		}.WithDefaults()
+
+		expected := defaults
+		expected.VotingDeadline = 10 * time.Second
+		expected.LobbyCountdown = 5 * time.Second
+		assert.Equal(t, withoutFormula(t, expected), withoutFormula(t, timings))
+		assert.Equal(t, time.Minute, timings.DayDeadline(3, 2, 10))
+	})
+	t.Run("Fake voting range", func(t *testing.T) {
+		t.Parallel()
+		// The min is above the default max.
+		timings := timePack.Timings{FakeVotingMin: defaults.FakeVotingMax + time.Second}.WithDefaults()
+		assert.Equal(t, defaults.FakeVotingMax+time.Second, timings.FakeVotingMin)
+		assert.Equal(t, timings.FakeVotingMin, timings.FakeVotingMax)
+
+		// The swapped range is fixed.
+		timings = timePack.Timings{FakeVotingMin: 8 * time.Second, FakeVotingMax: 2 * time.Second}.WithDefaults()
+		assert.Equal(t, 2*time.Second, timings.FakeVotingMin)
+		assert.Equal(t, 8*time.Second, timings.FakeVotingMax)
+	})
+}
+
+func TestTimings_GetVotingDeadline(t *testing.T) {
+	t.Parallel()
+	timings := timePack.Timings{
+		VotingDeadline: 30 * time.Second,
+		RolesVotingDeadlines: map[string]time.Duration{
+			"Mafia":  time.Minute,
+			"Doctor": 0,
+		},
+	}.WithDefaults()
+
+	assert.Equal(t, time.Minute, timings.GetVotingDeadline("Mafia"))
+	// Zero deadline of the role means the common one.
+	assert.Equal(t, 30*time.Second, timings.GetVotingDeadline("Doctor"))
+	assert.Equal(t, 30*time.Second, timings.GetVotingDeadline("Detective"))
+
+	defaults := timePack.Timings{}.WithDefaults()
+	assert.Equal(t, timePack.VotingDeadline*time.Second, defaults.GetVotingDeadline("Mafia"))
+}
@@ -1,6 +1,7 @@
 package player
 
 import (
+	"sort"
 	"strconv"
 
 	"github.com/https-whoyan/MafiaCore/roles"
@@ -125,6 +126,16 @@ func (s *Players) Len() int {
 	return len(*s)
 }
 
+// GetIDs returns sorted IDs of the players.
+func (s *Players) GetIDs() []IDType {
+	ids := make([]IDType, 0, len(*s))
+	for id := range *s {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
 func (s *Players) GetByID(ID int) *Player {
 	return (*s)[IDType(ID)]
 }
@@ -167,6 +178,28 @@ func (s *DeadPlayers) Add(players ...*DeadPlayer) {
 	}
 }
 
+// ToAlive returns the dead player back to the players (alive and not muted).
+// Returns nil, if there is no dead player with playerID.
+func (s *DeadPlayers) ToAlive(playerID IDType, players *Players) *Player {
+	for role, deadPlayers := range *s {
+		for i, deadPlayer := range deadPlayers {
+			if deadPlayer.ID != playerID {
+				continue
+			}
+			(*s)[role] = append(deadPlayers[:i], deadPlayers[i+1:]...)
+			if len((*s)[role]) == 0 {
+				delete(*s, role)
+			}
+			p := deadPlayer.Player
+			p.LifeStatus = Alive
+			p.InteractionStatus = Passed
+			(*players)[p.ID] = &p
+			return &p
+		}
+	}
+	return nil
+}
+
 func (s *DeadPlayers) ConvertToPlayers() *Players {
 	players := make(Players)
 	for _, rolePlayers := range *s {
@@ -3,6 +3,7 @@ package player
 import (
 	"errors"
 	"github.com/https-whoyan/MafiaCore/config"
+	"math/rand"
 )
 
 // ___________________________________
@@ -20,6 +21,13 @@ func generateListToN(n int) []int {
 }
 
 func GeneratePlayers(tags []string, oldUsernames []string,
+	serverUsernames []string, cfg *config.RolesConfig) (Players, error) {
+	return GeneratePlayersWithRand(nil, tags, oldUsernames, serverUsernames, cfg)
+}
+
+// GeneratePlayersWithRand same as GeneratePlayers, but roles are dealt with rnd.
+// If rnd is nil, the global math/rand is used.
+func GeneratePlayersWithRand(rnd *rand.Rand, tags []string, oldUsernames []string,
 	serverUsernames []string, cfg *config.RolesConfig) (Players, error) {
 	if len(tags) != cfg.PlayersCount {
 		return nil, errors.New("unexpected mismatch of playing participants and configs")
@@ -30,7 +38,7 @@ func GeneratePlayers(tags []string, oldUsernames []string,
 
 	n := len(tags)
 	IDs := generateListToN(n)
-	rolesArr := cfg.GetShuffledRolesConfig()
+	rolesArr := cfg.GetShuffledRolesConfigWithRand(rnd)
 
 	players := make(Players)
 
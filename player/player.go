@@ -45,6 +45,8 @@ type NonPlayingPlayer struct {
 	OldNick string `json:"oldNick" bson:"oldNick" db:"oldNick" yaml:"oldNick" xml:"oldNick" xlsx:"oldNick"`
 	// Nick after renaming.
 	Nick string `json:"nick" bson:"nick" db:"nick" yaml:"nick" xml:"nick" xlsx:"nick"`
+	// IsBot the seat is filled by the program (see game BotPlayer).
+	IsBot bool `json:"isBot,omitempty" bson:"isBot,omitempty" db:"isBot" yaml:"isBot,omitempty" xml:"isBot,omitempty" xlsx:"isBot"`
 }
 
 // For interfacing all structs
@@ -86,6 +88,10 @@ type DeadReason string
 const (
 	KilledAtNight     DeadReason = "KilledAtNight"
 	KilledByDayVoting DeadReason = "KilledByDayVoting"
+	// Left the player has left the game (disconnected or AFK).
+	Left DeadReason = "Left"
+	// KilledByHost the player is removed by the host of the game (see game ForceKill).
+	KilledByHost DeadReason = "KilledByHost"
 )
 
 func NewDeadPlayer(p *Player, reason DeadReason, dayLived int) *DeadPlayer {
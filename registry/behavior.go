@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"github.com/https-whoyan/MafiaCore/channel"
+	"github.com/https-whoyan/MafiaCore/fmt"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+// This file describes what the game knows about the behavior of the role.
+// roles.Role presents only the data of the role (name, team, orders),
+// RoleBehavior presents its code.
+
+// InteractionMessage is the result of the night action, which is sent to the role channel.
+type InteractionMessage string
+
+// Env is the part of the game that is available to the role behaviors.
+//
+// All RoleBehavior methods are called by the game under its lock, so the
+// implementation must not call the game methods, only the Env ones.
+type Env interface {
+	// Active presents players who are still in the game.
+	Active() *player.Players
+	// Dead presents players who are already out of the game.
+	Dead() *player.DeadPlayers
+	// FMT is used to format InteractionMessage.
+	FMT() fmt.FmtInterface
+	// RoleChannel returns the channel of the role, or nil if the game does not have it.
+	RoleChannel(role *roles.Role) channel.RoleChannel
+	// NightCounter presents the number of the current night.
+	NightCounter() int
+}
+
+// RoleBehavior presents everything the role does in the game.
+//
+// Use BaseBehavior to implement only the methods you need.
+type RoleBehavior interface {
+	// NightAction applies the player's last night vote.
+	//
+	// If the role has UrgentCalculation, it is called right after the role's vote,
+	// and a non-nil message is sent to the role channel. Otherwise, it is called
+	// after the night in CalculationOrder, and the message is ignored.
+	NightAction(env Env, p *player.Player) *InteractionMessage
+	// Reincarnation is called for each active player after the night.
+	// It is used if the role must become a different role in certain scenarios.
+	Reincarnation(env Env, p *player.Player) error
+	// WinCondition presents the condition under which the role wins on its own,
+	// regardless of its team.
+	//
+	// nil, if the role plays only for its team.
+	WinCondition() WinCondition
+}
+
+// Checker is the optional extension of RoleBehavior, for the roles, which check the players at night.
+//
+// CheckResult presents the same as the InteractionMessage of NightAction, but for the programs
+// (for example, for the bots, see game.BotPlayer), which can not read the messages.
+type Checker interface {
+	// Check returns the result of the last night vote of p, or nil, if the vote is empty.
+	Check(env Env, p *player.Player) *CheckResult
+}
+
+// CheckResult presents what the role has learned at night.
+type CheckResult struct {
+	Targets []player.IDType `json:"targets"`
+	// Role of the only target, if the role is revealed (Don).
+	Role *roles.Role `json:"role,omitempty"`
+	// IsSameTeam reports whether the targets are in one team (Detective).
+	IsSameTeam bool `json:"isSameTeam"`
+}
+
+// Check calls Check of the behavior of the role, if it implements Checker.
+func Check(env Env, p *player.Player) *CheckResult {
+	checker, ok := GetBehavior(p.Role).(Checker)
+	if !ok {
+		return nil
+	}
+	return checker.Check(env, p)
+}
+
+// WinCondition presents the condition of the end of the game.
+type WinCondition interface {
+	IsWon(env Env) bool
+}
+
+// BaseBehavior is a role without any actions.
+// Embed it in your behavior to implement only the methods you need.
+type BaseBehavior struct{}
+
+func (BaseBehavior) NightAction(_ Env, _ *player.Player) *InteractionMessage { return nil }
+func (BaseBehavior) Reincarnation(_ Env, _ *player.Player) error             { return nil }
+func (BaseBehavior) WinCondition() WinCondition                              { return nil }
+
+// _______________
+// Helpers
+// _______________
+
+// emptyVote same as game.EmptyVoteInt.
+const emptyVote player.IDType = -1
+
+// LastVoteTarget returns the active player the p voted for on the last vote.
+//
+// isEmpty is true if p has no votes, or his last vote is empty.
+func LastVoteTarget(env Env, p *player.Player) (target *player.Player, isEmpty bool) {
+	if len(p.Votes) == 0 {
+		return nil, true
+	}
+	lastVote := p.Votes[len(p.Votes)-1]
+	if lastVote == emptyVote {
+		return nil, true
+	}
+	target = env.Active().GetByIDType(lastVote)
+	return target, target == nil
+}
+
+// LastTwoVotesTargets same as LastVoteTarget, but for the roles with roles.Role IsTwoVotes.
+func LastTwoVotesTargets(env Env, p *player.Player) (target1, target2 *player.Player, isEmpty bool) {
+	n := len(p.Votes)
+	if n < 2 {
+		return nil, nil, true
+	}
+	if p.Votes[n-1] == emptyVote || p.Votes[n-2] == emptyVote {
+		return nil, nil, true
+	}
+	target1 = env.Active().GetByIDType(p.Votes[n-2])
+	target2 = env.Active().GetByIDType(p.Votes[n-1])
+	return target1, target2, target1 == nil || target2 == nil
+}
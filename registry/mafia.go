@@ -0,0 +1,91 @@
+package registry
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+	"github.com/samber/lo"
+)
+
+/* Mafia */
+
+type MafiaBehavior struct{ BaseBehavior }
+
+func (MafiaBehavior) NightAction(env Env, mafia *player.Player) *InteractionMessage {
+	nextDeadPlayer, isEmpty := LastVoteTarget(env, mafia)
+	if isEmpty {
+		return nil
+	}
+
+	nextDeadPlayer.LifeStatus = player.Dead
+	return nil
+}
+
+/* Don */
+
+type DonBehavior struct{ BaseBehavior }
+
+func (DonBehavior) NightAction(env Env, don *player.Player) *InteractionMessage {
+	f := env.FMT()
+
+	checkedPlayer, isEmpty := LastVoteTarget(env, don)
+	if isEmpty {
+		return nil
+	}
+
+	message := InteractionMessage("Checked player " + f.Block(strconv.Itoa(int(checkedPlayer.ID))) + ", role: " +
+		f.Block(checkedPlayer.Role.Name))
+	return &message
+}
+
+func (DonBehavior) Check(env Env, don *player.Player) *CheckResult {
+	checkedPlayer, isEmpty := LastVoteTarget(env, don)
+	if isEmpty {
+		return nil
+	}
+	return &CheckResult{
+		Targets: []player.IDType{checkedPlayer.ID},
+		Role:    checkedPlayer.Role,
+	}
+}
+
+// Reincarnation
+// If the don is the only one left on the mafia team, he becomes mafia.
+func (DonBehavior) Reincarnation(env Env, don *player.Player) error {
+	mafiaTeamCounter := lo.CountValues(lo.Map(
+		lo.Values(*env.Active()),
+		func(p *player.Player, _ int) roles.Team {
+			return p.Role.Team
+		}),
+	)[roles.MafiaTeam]
+	if mafiaTeamCounter > 1 {
+		return nil
+	}
+	don.Role = roles.Mafia
+
+	var err error
+	if donChannel := env.RoleChannel(roles.Don); donChannel != nil {
+		if removeErr := donChannel.RemoveUser(don.Tag); removeErr != nil {
+			err = multierror.Append(err, removeErr)
+		}
+	}
+	mafiaChannel := env.RoleChannel(roles.Mafia)
+	if mafiaChannel == nil {
+		return err
+	}
+	if addErr := mafiaChannel.AddPlayer(don.Tag); addErr != nil {
+		err = multierror.Append(err, addErr)
+	}
+
+	f := env.FMT()
+	var message string
+	message = f.Bold("Hello, dear ") + f.Mention(don.ServerNick) + "." + f.LineSplitter()
+	message += "You are the last player left alive from the mafia team, so you become mafia." + f.LineSplitter()
+	message += f.Underline("Don't reveal yourself.")
+	if _, writeErr := mafiaChannel.Write([]byte(message)); writeErr != nil {
+		err = multierror.Append(err, writeErr)
+	}
+	return err
+}
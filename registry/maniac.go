@@ -0,0 +1,18 @@
+package registry
+
+import "github.com/https-whoyan/MafiaCore/player"
+
+/* Maniac */
+
+type ManiacBehavior struct{ BaseBehavior }
+
+func (ManiacBehavior) NightAction(env Env, maniac *player.Player) *InteractionMessage {
+	// Same as mafia
+	nextDeadPlayer, isEmpty := LastVoteTarget(env, maniac)
+	if isEmpty {
+		return nil
+	}
+
+	nextDeadPlayer.LifeStatus = player.Dead
+	return nil
+}
@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"strconv"
+
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+/* Peaceful */
+
+type PeacefulBehavior struct{ BaseBehavior }
+
+/* Fool */
+
+type FoolBehavior struct{ BaseBehavior }
+
+func (FoolBehavior) WinCondition() WinCondition { return foolWinCondition{} }
+
+// foolWinCondition the fool wins, if he is ousted during the day's voting.
+type foolWinCondition struct{}
+
+func (foolWinCondition) IsWon(env Env) bool {
+	for _, fool := range (*env.Dead())[roles.Fool] {
+		if fool.DeadReason == player.KilledByDayVoting {
+			return true
+		}
+	}
+	return false
+}
+
+/* Doctor */
+
+type DoctorBehavior struct{ BaseBehavior }
+
+func (DoctorBehavior) NightAction(env Env, doctor *player.Player) *InteractionMessage {
+	toVotedPlayer, isEmpty := LastVoteTarget(env, doctor)
+	if isEmpty {
+		return nil
+	}
+
+	if toVotedPlayer.LifeStatus == player.Dead {
+		toVotedPlayer.LifeStatus = player.Alive
+	}
+	return nil
+}
+
+/* Detective */
+
+type DetectiveBehavior struct{ BaseBehavior }
+
+func (DetectiveBehavior) NightAction(env Env, detective *player.Player) *InteractionMessage {
+	checkedPlayer1, checkedPlayer2, isEmpty := LastTwoVotesTargets(env, detective)
+	if isEmpty {
+		return nil
+	}
+
+	f := env.FMT()
+	isEqualsTeams := checkedPlayer1.Role.Team == checkedPlayer2.Role.Team
+
+	message := "Players with id's " + f.Block(strconv.Itoa(int(checkedPlayer1.ID))) + ", " +
+		f.Block(strconv.Itoa(int(checkedPlayer2.ID)))
+	if isEqualsTeams {
+		message += f.Bold(" in one team.")
+	} else {
+		message += f.Bold(" in different team.")
+	}
+	typedMessage := InteractionMessage(message)
+	return &typedMessage
+}
+
+func (DetectiveBehavior) Check(env Env, detective *player.Player) *CheckResult {
+	checkedPlayer1, checkedPlayer2, isEmpty := LastTwoVotesTargets(env, detective)
+	if isEmpty {
+		return nil
+	}
+	return &CheckResult{
+		Targets:    []player.IDType{checkedPlayer1.ID, checkedPlayer2.ID},
+		IsSameTeam: checkedPlayer1.Role.Team == checkedPlayer2.Role.Team,
+	}
+}
+
+/* Whore */
+
+type WhoreBehavior struct{ BaseBehavior }
+
+func (WhoreBehavior) NightAction(env Env, whore *player.Player) *InteractionMessage {
+	mutedPlayer, isEmpty := LastVoteTarget(env, whore)
+	if isEmpty {
+		return nil
+	}
+	mutedPlayer.InteractionStatus = player.Muted
+	return nil
+}
+
+/* Citizen */
+
+type CitizenBehavior struct{ BaseBehavior }
+
+func (CitizenBehavior) NightAction(env Env, citizen *player.Player) *InteractionMessage {
+	defendedPlayer, isEmpty := LastVoteTarget(env, citizen)
+	if isEmpty {
+		return nil
+	}
+
+	// Citizen is calculated by the most recent, then, if a civilian was killed, her
+	// status would definitely be dead.
+	defendedPlayer.LifeStatus = player.Alive
+	if citizen.LifeStatus == player.Dead {
+		defendedPlayer.LifeStatus = player.Dead
+	}
+	return nil
+}
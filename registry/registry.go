@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+// Presents all known behaviors of the roles.
+//
+// Built-in roles are registered in init, you can register your own roles
+// (or replace the behavior of the built-in ones) with Register.
+
+var (
+	mu        sync.RWMutex
+	behaviors = make(map[*roles.Role]RoleBehavior)
+)
+
+var (
+	NilRoleErr              = errors.New("nil role")
+	EmptyRoleNameErr        = errors.New("empty role name")
+	NilBehaviorErr          = errors.New("nil role behavior")
+	RoleNameAlreadyTakenErr = errors.New("role name already taken by another role")
+)
+
+// Register links the role with its behavior and adds the role to roles.MappedRoles,
+// so it can be used in configs.
+//
+// If the role is already registered, its behavior will be replaced.
+// It can be called at runtime, while the games are played.
+func Register(role *roles.Role, behavior RoleBehavior) error {
+	if role == nil {
+		return NilRoleErr
+	}
+	if role.Name == "" {
+		return EmptyRoleNameErr
+	}
+	if behavior == nil {
+		return NilBehaviorErr
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !roles.MapRole(role) {
+		return fmt.Errorf("%w: %v", RoleNameAlreadyTakenErr, role.Name)
+	}
+	behaviors[role] = behavior
+	return nil
+}
+
+// MustRegister same as Register, but panics on error.
+// Useful in init functions.
+func MustRegister(role *roles.Role, behavior RoleBehavior) {
+	if err := Register(role, behavior); err != nil {
+		panic(err)
+	}
+}
+
+// GetBehavior returns the behavior of the role.
+// If the role is not registered, returns BaseBehavior.
+func GetBehavior(role *roles.Role) RoleBehavior {
+	mu.RLock()
+	defer mu.RUnlock()
+	if behavior, ok := behaviors[role]; ok {
+		return behavior
+	}
+	return BaseBehavior{}
+}
+
+// IsRegistered reports whether the role has its behavior.
+func IsRegistered(role *roles.Role) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := behaviors[role]
+	return ok
+}
+
+// GetWinConditions returns the win conditions of all registered roles that win on their own.
+func GetWinConditions() map[*roles.Role]WinCondition {
+	mu.RLock()
+	defer mu.RUnlock()
+	conditions := make(map[*roles.Role]WinCondition)
+	for role, behavior := range behaviors {
+		if condition := behavior.WinCondition(); condition != nil {
+			conditions[role] = condition
+		}
+	}
+	return conditions
+}
+
+func init() {
+	MustRegister(roles.Mafia, MafiaBehavior{})
+	MustRegister(roles.Don, DonBehavior{})
+	MustRegister(roles.Maniac, ManiacBehavior{})
+	MustRegister(roles.Peaceful, PeacefulBehavior{})
+	MustRegister(roles.Fool, FoolBehavior{})
+	MustRegister(roles.Doctor, DoctorBehavior{})
+	MustRegister(roles.Detective, DetectiveBehavior{})
+	MustRegister(roles.Whore, WhoreBehavior{})
+	MustRegister(roles.Citizen, CitizenBehavior{})
+}
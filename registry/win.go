@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/https-whoyan/MafiaCore/channel"
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/fmt"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+// This file contains the win conditions of the teams and the helpers to build them.
+//
+// The game checks the win conditions after every night and day:
+// first the solo conditions of the roles (see RoleBehavior WinCondition), then the conditions of the teams.
+// Built-in conditions can be replaced with RegisterTeamWinCondition,
+// or only in one game with the game options (house rules).
+
+var NilWinConditionErr = errors.New("nil win condition")
+
+var (
+	teamsMu           sync.RWMutex
+	teamWinConditions = map[roles.Team]WinCondition{
+		// Peaceful win, only if all others are out.
+		roles.PeacefulTeam: LastTeamStanding(roles.PeacefulTeam),
+		// Mafia and Maniac can kill everyone else, if there are no fewer of them than others.
+		roles.MafiaTeam:  AnyOf(LastTeamStanding(roles.MafiaTeam), TeamParity(roles.MafiaTeam)),
+		roles.ManiacTeam: AnyOf(LastTeamStanding(roles.ManiacTeam), TeamParity(roles.ManiacTeam)),
+	}
+)
+
+// RegisterTeamWinCondition sets the win condition of the team in all games.
+func RegisterTeamWinCondition(team roles.Team, condition WinCondition) error {
+	if condition == nil {
+		return NilWinConditionErr
+	}
+	teamsMu.Lock()
+	defer teamsMu.Unlock()
+	teamWinConditions[team] = condition
+	return nil
+}
+
+// GetTeamWinCondition returns the win condition of the team, or nil, if the team does not have it.
+func GetTeamWinCondition(team roles.Team) WinCondition {
+	teamsMu.RLock()
+	defer teamsMu.RUnlock()
+	return teamWinConditions[team]
+}
+
+// _______________
+// Helpers
+// _______________
+
+// WinConditionFunc allows to use the function as WinCondition.
+type WinConditionFunc func(env Env) bool
+
+func (f WinConditionFunc) IsWon(env Env) bool { return f(env) }
+
+func countActiveByTeam(env Env) map[roles.Team]int {
+	teams := make(map[roles.Team]int)
+	for _, p := range *env.Active() {
+		teams[p.Role.Team]++
+	}
+	return teams
+}
+
+// LastTeamStanding the team wins, if all active players are from it.
+func LastTeamStanding(team roles.Team) WinCondition {
+	return WinConditionFunc(func(env Env) bool {
+		teams := countActiveByTeam(env)
+		return len(teams) == 1 && teams[team] > 0
+	})
+}
+
+// TeamParity the team wins, if its active players are not fewer than all other active players.
+// For example, 3 mafia against 2 peaceful and 1 maniac.
+func TeamParity(team roles.Team) WinCondition {
+	return WinConditionFunc(func(env Env) bool {
+		teams := countActiveByTeam(env)
+		teamCount := teams[team]
+		othersCount := env.Active().Len() - teamCount
+		return teamCount > 0 && teamCount >= othersCount
+	})
+}
+
+// RoleIsOut the condition is done, if there are no active players with the role.
+// Useful for house rules, for example, "mafia wins at parity only if no Doctor alive".
+func RoleIsOut(role *roles.Role) WinCondition {
+	return WinConditionFunc(func(env Env) bool {
+		return env.Active().SearchAllPlayersWithRole(role).Len() == 0
+	})
+}
+
+// AnyOf the condition is done, if one of the conditions is done.
+func AnyOf(conditions ...WinCondition) WinCondition {
+	return WinConditionFunc(func(env Env) bool {
+		for _, condition := range conditions {
+			if condition.IsWon(env) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// AllOf the condition is done, if all the conditions are done.
+func AllOf(conditions ...WinCondition) WinCondition {
+	return WinConditionFunc(func(env Env) bool {
+		for _, condition := range conditions {
+			if !condition.IsWon(env) {
+				return false
+			}
+		}
+		return len(conditions) > 0
+	})
+}
+
+// _______________
+// Config
+// _______________
+
+// configEnv presents the game with the config before the first night: everyone is alive.
+type configEnv struct {
+	active *player.Players
+}
+
+func (e configEnv) Active() *player.Players                       { return e.active }
+func (e configEnv) Dead() *player.DeadPlayers                     { return &player.DeadPlayers{} }
+func (e configEnv) FMT() fmt.FmtInterface                         { return nil }
+func (e configEnv) RoleChannel(_ *roles.Role) channel.RoleChannel { return nil }
+func (e configEnv) NightCounter() int                             { return 0 }
+
+// ConfigWinner returns config.WinnerFunc, which checks the win conditions of the teams on the config.
+// Use it with config.ValidateWinnerOpt or config.GeneratorWinnerOpt.
+//
+// order and conditions are the house rules (see game TeamWinConditionOpt),
+// the teams without them are checked with the registered conditions.
+func ConfigWinner(order []roles.Team, conditions map[roles.Team]WinCondition) config.WinnerFunc {
+	return func(cfg *config.RolesConfig) *roles.Team {
+		active := make(player.Players)
+		for _, roleCfg := range cfg.RolesMp {
+			for i := 0; i < roleCfg.Count; i++ {
+				id := player.IDType(len(active) + 1)
+				active[id] = player.NewPlayer(id, "", "", "", roleCfg.Role)
+			}
+		}
+		env := configEnv{active: &active}
+
+		teams := append([]roles.Team{}, order...)
+		for _, team := range cfg.GetTeamsByCfg() {
+			if !slices.Contains(teams, team) {
+				teams = append(teams, team)
+			}
+		}
+		for _, team := range teams {
+			condition, isHouseRule := conditions[team]
+			if !isHouseRule {
+				condition = GetTeamWinCondition(team)
+			}
+			if condition != nil && condition.IsWon(env) {
+				return &team
+			}
+		}
+		return nil
+	}
+}
+
+func init() {
+	// The generator does not generate the configs, in which a team has already won at the start.
+	config.DefaultWinner = ConfigWinner(nil, nil)
+}
@@ -3,7 +3,7 @@ package roles
 var Citizen = &Role{
 	Name:              "Citizen",
 	Team:              PeacefulTeam,
-	UrgentCalculation: true,
+	UrgentCalculation: false,
 	CalculationOrder:  4,
 	NightVoteOrder:    2,
 	Description: `
@@ -35,7 +35,7 @@ func FixDescription(description string) string {
 
 func GetDefinitionOfRole(f fmt.FmtInterface, roleName string) string {
 
-	role := MappedRoles[roleName]
+	role, _ := GetRoleByName(roleName)
 	var message string
 
 	name := f.Block(role.Name)
@@ -9,10 +9,16 @@ type Role struct {
 	// Allows for calculations to be made in the correct order after night.
 	CalculationOrder int
 	// Presents whether 2 player IDs are used in night actions of the role at once.
-	IsTwoVotes  bool
+	IsTwoVotes bool
+	// Whom the role can target at night.
+	TargetRules TargetRules
 	Description string
 }
 
+// MappedRoles presents all known roles by names.
+//
+// The roles can be added at runtime (see MapRole), so read it only by the functions of the package
+// (GetRoleByName, GetAllSortedRoles and others), they are guarded by the lock.
 var MappedRoles = map[string]*Role{
 	"Citizen":   Citizen,
 	"Detective": Detective,
@@ -0,0 +1,20 @@
+package roles
+
+// TargetRules presents whom the player with the role can target at night.
+//
+// The rules are checked by the game on every night vote, together with the rules of the game
+// (voting for yourself, vote ping). The empty votes are always allowed.
+//
+// Can be replaced per game (see game.TargetRulesOpt).
+type TargetRules struct {
+	// NoSelfTarget the player can not target himself.
+	NoSelfTarget bool
+	// SelfTargetLimit the player can target himself so many times per game,
+	// even if the game does not allow voting for yourself (the Doctor self-heals once per game, for example).
+	// 0 - the game decides.
+	SelfTargetLimit int
+	// NoRepeatTarget the player can not target the same player two nights in a row.
+	NoRepeatTarget bool
+	// NoTeammateTarget the player can not target the players of his team.
+	NoTeammateTarget bool
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/samber/lo"
 	"slices"
 	"sort"
+	"sync"
 )
 
 // Utils.
@@ -12,7 +13,24 @@ import (
 // This contains all the functions that link the role name to the role, and things like that.
 // __________________
 
+// mappedRolesMu guards MappedRoles.
+var mappedRolesMu sync.RWMutex
+
+// MapRole adds the role to MappedRoles, if its name is not taken by another role.
+// Returns false, if the name is taken. Used by registry.Register.
+func MapRole(role *Role) bool {
+	mappedRolesMu.Lock()
+	defer mappedRolesMu.Unlock()
+	if mappedRole, ok := MappedRoles[role.Name]; ok && mappedRole != role {
+		return false
+	}
+	MappedRoles[role.Name] = role
+	return true
+}
+
 func GetAllNightInteractionRolesNames() []string {
+	mappedRolesMu.RLock()
+	defer mappedRolesMu.RUnlock()
 	return lo.Filter(
 		lo.MapToSlice(
 			MappedRoles,
@@ -46,12 +64,16 @@ func GetAllRolesNames() []string {
 }
 
 func GetRoleByName(roleName string) (*Role, bool) {
+	mappedRolesMu.RLock()
+	defer mappedRolesMu.RUnlock()
 	role, ok := MappedRoles[roleName]
 	return role, ok
 }
 
 func GetAllSortedRoles() []*Role {
+	mappedRolesMu.RLock()
 	allRoles := lo.Values(MappedRoles)
+	mappedRolesMu.RUnlock()
 
 	sort.Slice(allRoles, func(i, j int) bool {
 		return allRoles[i].Team < allRoles[j].Team
@@ -62,9 +84,11 @@ func GetAllSortedRoles() []*Role {
 
 func GetAllTeams() []Team {
 	mpTeams := make(map[Team]bool)
+	mappedRolesMu.RLock()
 	for _, role := range MappedRoles {
 		mpTeams[role.Team] = true
 	}
+	mappedRolesMu.RUnlock()
 
 	teamsSlice := lo.Keys(mpTeams)
 	sort.Slice(teamsSlice, func(i, j int) bool {
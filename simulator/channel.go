@@ -0,0 +1,36 @@
+package simulator
+
+import (
+	"github.com/https-whoyan/MafiaCore/roles"
+)
+
+// Nobody reads the messages of the simulated games, so the channels only count them.
+
+type memoryChannel struct {
+	serverID string
+	messages int
+}
+
+func (c *memoryChannel) Write(b []byte) (int, error) {
+	c.messages++
+	return len(b), nil
+}
+
+func (c *memoryChannel) AddPlayer(_ string) error    { return nil }
+func (c *memoryChannel) AddSpectator(_ string) error { return nil }
+func (c *memoryChannel) RemoveUser(_ string) error   { return nil }
+func (c *memoryChannel) GetServerID() string         { return c.serverID }
+
+type memoryRoleChannel struct {
+	memoryChannel
+	role *roles.Role
+}
+
+func newMemoryRoleChannel(role *roles.Role) *memoryRoleChannel {
+	return &memoryRoleChannel{
+		memoryChannel: memoryChannel{serverID: role.Name},
+		role:          role,
+	}
+}
+
+func (c *memoryRoleChannel) GetRole() *roles.Role { return c.role }
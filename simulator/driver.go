@@ -0,0 +1,245 @@
+package simulator
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/https-whoyan/MafiaCore/bot"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+	timePack "github.com/https-whoyan/MafiaCore/time"
+)
+
+// driver plays one game: it votes for the bots on the signals of the game,
+// and fires the timers of the game, when nobody is going to vote.
+//
+// The game always sends the signal before it starts the timer of the voting,
+// so, if there are no signals and the bots have voted, the timer can be fired.
+type driver struct {
+	s     *Simulator
+	g     *game.Game
+	clock *timePack.FakeClock
+	rand  *rand.Rand
+	// cancel stops the game, if it is too long (see MaxNightsOpt) or nobody can win.
+	cancel    context.CancelFunc
+	isStopped bool
+
+	// Closed, when the bots have cast all votes of the day voting.
+	// nil, if there is no day voting now.
+	dayVoted chan struct{}
+	// Closed, when the day voting is replaced by the next one.
+	stopDayVoting chan struct{}
+}
+
+// drive handles the signals of the game until it is finished.
+func (d *driver) drive(infoCh <-chan game.InfoSignal) {
+	for {
+		// The signals always first.
+		select {
+		case s := <-infoCh:
+			if d.handle(s) {
+				return
+			}
+			continue
+		default:
+		}
+
+		if d.dayVoted != nil {
+			select {
+			case s := <-infoCh:
+				if d.handle(s) {
+					return
+				}
+			case <-d.dayVoted:
+				d.dayVoted = nil
+			}
+			continue
+		}
+
+		waitCtx, cancelWait := context.WithCancel(context.Background())
+		timerIsWaiting := make(chan struct{})
+		go func() {
+			if d.clock.BlockUntilContext(waitCtx, 1) == nil {
+				close(timerIsWaiting)
+			}
+		}()
+		select {
+		case s := <-infoCh:
+			cancelWait()
+			if d.handle(s) {
+				return
+			}
+		case <-timerIsWaiting:
+			cancelWait()
+			select {
+			case s := <-infoCh:
+				if d.handle(s) {
+					return
+				}
+			default:
+				d.clock.AdvanceToNext()
+			}
+		}
+	}
+}
+
+// handle returns true, if the game is finished.
+func (d *driver) handle(s game.InfoSignal) bool {
+	switch info := s.Info.(type) {
+	case game.FinishGameInfo:
+		d.stopDayVotes()
+		return true
+	case game.SwitchStateInfo:
+		// Nobody can win, if everyone is out.
+		isDraw := len(d.g.GetActivePlayers()) == 0
+		isTooLong := info.NewState == game.NightState && d.g.GetNightsCount() >= d.s.maxNights
+		if (isDraw || isTooLong) && !d.isStopped {
+			d.isStopped = true
+			d.cancel()
+		}
+	case game.SwitchVotingRoleInfo:
+		if !d.isStopped && info.CurrVotingRole != nil {
+			d.nightVote(info)
+		}
+	case game.SwitchDayPhaseInfo:
+		d.stopDayVotes()
+		if !d.isStopped && info.DayPhase.IsVoting() {
+			d.dayVotes(info)
+		}
+	}
+	return false
+}
+
+// knowledge returns what the bot p knows about the game.
+// Only mafia know each other, because they share the channel.
+func (d *driver) knowledge(p *player.Player, active player.Players) *bot.Knowledge {
+	k := &bot.Knowledge{
+		ID:   p.ID,
+		Role: p.Role,
+	}
+	if p.Role.Team == roles.MafiaTeam {
+		for _, ally := range d.sortedActive(active) {
+			if ally.Role.Team == roles.MafiaTeam {
+				k.Teammates = append(k.Teammates, ally.ID)
+			}
+		}
+	}
+	return k
+}
+
+func (d *driver) sortedActive(active player.Players) []*player.Player {
+	players := make([]*player.Player, 0, len(active))
+	for _, p := range active {
+		players = append(players, p)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
+
+// ____________
+// Night
+// ____________
+
+// nightVote votes for the first not muted player with the role, the game decides for the role by its TeamVotePolicy.
+func (d *driver) nightVote(info game.SwitchVotingRoleInfo) {
+	role := info.CurrVotingRole
+	active := d.g.GetActivePlayers()
+	var voter *player.Player
+	for _, p := range d.sortedActive(active) {
+		if p.Role == role && p.InteractionStatus != player.Muted {
+			voter = p
+			break
+		}
+	}
+	// The game waits for its fake timer.
+	if voter == nil {
+		return
+	}
+
+	nightInfo := game.BotNightInfo{
+		Night:  d.g.GetNightsCount(),
+		Role:   role,
+		Active: active.GetIDs(),
+	}
+	targets := d.s.strategy(role.Team).NightTargets(d.knowledge(voter, active), nightInfo, d.rand)
+	voterID := strconv.Itoa(int(voter.ID))
+	if role.IsTwoVotes {
+		for i := 0; i+1 < len(targets); i += 2 {
+			err := d.g.SetNightTwoVote(game.NewTwoVoteProvider(
+				voterID, strconv.Itoa(int(targets[i])), strconv.Itoa(int(targets[i+1])), false, false,
+			))
+			if err == nil {
+				return
+			}
+		}
+		return
+	}
+	for _, target := range targets {
+		if d.g.SetNightVote(game.NewVoteProvider(voterID, strconv.Itoa(int(target)), false, false)) == nil {
+			return
+		}
+	}
+	// No accepted targets, the timer of the role will be fired.
+}
+
+// ____________
+// Day
+// ____________
+
+// dayVotes casts the votes of all bots in the random order.
+//
+// The voting may end before all votes (see game.DayVotingRules), and the game opens the next one
+// only after its signal is received. So the votes are cast in the goroutine, while the driver is free.
+func (d *driver) dayVotes(info game.SwitchDayPhaseInfo) {
+	active := d.g.GetActivePlayers()
+	dayInfo := game.BotDayInfo{
+		Day:        d.g.GetNightsCount(),
+		DayPhase:   info.DayPhase,
+		Candidates: info.Candidates,
+		Active:     active.GetIDs(),
+	}
+	dayVotes := make(map[player.IDType]player.IDType)
+
+	voters := d.sortedActive(active)
+	d.rand.Shuffle(len(voters), func(i, j int) { voters[i], voters[j] = voters[j], voters[i] })
+	targets := make([]player.IDType, len(voters))
+	// The decisions are made here, so the rand of the driver is used only by one goroutine.
+	// Every bot sees the votes of the bots before him.
+	for i, voter := range voters {
+		k := d.knowledge(voter, active)
+		k.DayVotes = dayVotes
+		targets[i] = d.s.strategy(voter.Role.Team).DayTarget(k, dayInfo, d.rand)
+		if targets[i] != game.EmptyVoteInt {
+			dayVotes[voter.ID] = targets[i]
+		}
+	}
+
+	dayVoted := make(chan struct{})
+	stop := make(chan struct{})
+	d.dayVoted, d.stopDayVoting = dayVoted, stop
+	go func() {
+		defer close(dayVoted)
+		for i, voter := range voters {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			voterID := strconv.Itoa(int(voter.ID))
+			err := d.g.SetDayVote(game.NewVoteProvider(voterID, strconv.Itoa(int(targets[i])), false, false))
+			if err != nil && targets[i] != game.EmptyVoteInt {
+				_ = d.g.SetDayVote(game.NewVoteProvider(voterID, game.EmptyVoteStr, false, false))
+			}
+		}
+	}()
+}
+
+func (d *driver) stopDayVotes() {
+	if d.stopDayVoting != nil {
+		close(d.stopDayVoting)
+		d.stopDayVoting = nil
+	}
+}
@@ -0,0 +1,346 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/https-whoyan/MafiaCore/bot"
+	"github.com/https-whoyan/MafiaCore/config"
+	"github.com/https-whoyan/MafiaCore/game"
+	"github.com/https-whoyan/MafiaCore/player"
+	"github.com/https-whoyan/MafiaCore/roles"
+	timePack "github.com/https-whoyan/MafiaCore/time"
+)
+
+// Simulator plays many headless games of the config with the bots,
+// to find out the win rates of the teams (Monte-Carlo).
+//
+// Every game is the real game.Game with the fake clock and the channels in memory,
+// the bots vote by their bot.Strategy (see StrategyOpt).
+// The games depend only on the seed (see SeedOpt), so the same seed gives the same results.
+
+var (
+	NilConfigErr    = errors.New("nil config")
+	IncorrectGames  = errors.New("incorrect number of games")
+	GameIsNotPlayed = errors.New("game is not played")
+)
+
+type Option func(s *Simulator)
+
+// GamesOpt sets the number of the games. Default - 1000.
+func GamesOpt(games int) Option {
+	return func(s *Simulator) { s.games = games }
+}
+
+// SeedOpt sets the seed of the first game, the next games have seed+1, seed+2, ...
+// Default - the time of the simulator creation.
+func SeedOpt(seed int64) Option {
+	return func(s *Simulator) { s.seed = seed }
+}
+
+// StrategyOpt sets the strategy of the bots of the team.
+//
+// Default - bot.TownStrategy for the peaceful team, bot.MafiaStrategy for the mafia team,
+// and bot.RandomStrategy for others.
+func StrategyOpt(team roles.Team, strategy bot.Strategy) Option {
+	return func(s *Simulator) { s.strategies[team] = strategy }
+}
+
+// ParallelismOpt sets the number of the games played at the same time. Default - runtime.NumCPU().
+func ParallelismOpt(parallelism int) Option {
+	return func(s *Simulator) { s.parallelism = max(parallelism, 1) }
+}
+
+// MaxNightsOpt sets the number of the nights, after which the game is stopped as unfinished. Default - 50.
+func MaxNightsOpt(maxNights int) Option {
+	return func(s *Simulator) { s.maxNights = maxNights }
+}
+
+// GameOptionsOpt sets the options of all games (day procedure, house rules and so on).
+// The clock, seed and loggers of the games are set by the simulator.
+func GameOptionsOpt(opts ...game.Option) Option {
+	return func(s *Simulator) { s.gameOpts = append(s.gameOpts, opts...) }
+}
+
+type Simulator struct {
+	cfg         *config.RolesConfig
+	games       int
+	seed        int64
+	strategies  map[roles.Team]bot.Strategy
+	parallelism int
+	maxNights   int
+	gameOpts    []game.Option
+}
+
+// New returns the simulator of the config. The config must be valid (see config.RolesConfig Validate).
+func New(cfg *config.RolesConfig, opts ...Option) (*Simulator, error) {
+	if cfg == nil {
+		return nil, NilConfigErr
+	}
+	if err := cfg.Validate().Err(); err != nil {
+		return nil, err
+	}
+	s := &Simulator{
+		cfg:   cfg,
+		games: 1000,
+		seed:  time.Now().UnixNano(),
+		strategies: map[roles.Team]bot.Strategy{
+			roles.PeacefulTeam: bot.TownStrategy{},
+			roles.MafiaTeam:    bot.MafiaStrategy{},
+		},
+		parallelism: runtime.NumCPU(),
+		maxNights:   50,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.games <= 0 {
+		return nil, fmt.Errorf("%w: %v", IncorrectGames, s.games)
+	}
+	return s, nil
+}
+
+func (s *Simulator) strategy(team roles.Team) bot.Strategy {
+	if strategy, ok := s.strategies[team]; ok {
+		return strategy
+	}
+	return bot.RandomStrategy{}
+}
+
+// ____________
+// Report
+// ____________
+
+// Result presents one simulated game.
+type Result struct {
+	Seed int64
+	// WinnerTeam is nil, if the game is won by SoloWinner or is unfinished.
+	WinnerTeam *roles.Team
+	SoloWinner string
+	IsFinished bool
+	Nights     int
+	// Duration of the game by the game clock.
+	// It is approximate: the timer of the day voting may be fired right after the last vote.
+	Duration time.Duration
+}
+
+// Report presents the results of all simulated games.
+type Report struct {
+	Games    int
+	TeamWins map[roles.Team]int
+	SoloWins map[string]int
+	// Unfinished are the games, stopped by MaxNightsOpt, and the draws (everyone is out at the same night).
+	Unfinished int
+	// Average of the finished games.
+	AverageNights   float64
+	AverageDuration time.Duration
+}
+
+// TeamWinRate returns the share of the games, won by the team.
+func (r *Report) TeamWinRate(team roles.Team) float64 {
+	if r.Games == 0 {
+		return 0
+	}
+	return float64(r.TeamWins[team]) / float64(r.Games)
+}
+
+// SoloWinRate returns the share of the games, won by the role on its own.
+func (r *Report) SoloWinRate(role *roles.Role) float64 {
+	if r.Games == 0 {
+		return 0
+	}
+	return float64(r.SoloWins[role.Name]) / float64(r.Games)
+}
+
+func (r *Report) String() string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Games: %v, unfinished: %v", r.Games, r.Unfinished))
+
+	teams := make([]roles.Team, 0, len(r.TeamWins))
+	for team := range r.TeamWins {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i] < teams[j] })
+	for _, team := range teams {
+		lines = append(lines, fmt.Sprintf("%v: %.1f%%", roles.StringTeam[team], 100*r.TeamWinRate(team)))
+	}
+	soloWinners := make([]string, 0, len(r.SoloWins))
+	for roleName := range r.SoloWins {
+		soloWinners = append(soloWinners, roleName)
+	}
+	sort.Strings(soloWinners)
+	for _, roleName := range soloWinners {
+		lines = append(lines, fmt.Sprintf("%v (solo): %.1f%%", roleName, 100*float64(r.SoloWins[roleName])/float64(r.Games)))
+	}
+	lines = append(lines, fmt.Sprintf("Average nights: %.2f, average duration: %v", r.AverageNights, r.AverageDuration))
+	return strings.Join(lines, "\n")
+}
+
+func (r *Report) add(result Result) {
+	r.Games++
+	switch {
+	case !result.IsFinished:
+		r.Unfinished++
+		return
+	case result.SoloWinner != "":
+		r.SoloWins[result.SoloWinner]++
+	case result.WinnerTeam != nil:
+		r.TeamWins[*result.WinnerTeam]++
+	}
+	finished := float64(r.Games - r.Unfinished)
+	r.AverageNights += (float64(result.Nights) - r.AverageNights) / finished
+	r.AverageDuration += time.Duration(float64(result.Duration-r.AverageDuration) / finished)
+}
+
+// ____________
+// Run
+// ____________
+
+// Run plays all games and returns the Report.
+// If ctx is done, the report of the already played games is returned with ctx.Err().
+func (s *Simulator) Run(ctx context.Context) (*Report, error) {
+	seeds := make(chan int64)
+	go func() {
+		defer close(seeds)
+		for i := 0; i < s.games; i++ {
+			select {
+			case seeds <- s.seed + int64(i):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		err error
+		// Key - seed, so the report does not depend on the order of the games.
+		results = make(map[int64]Result)
+	)
+	for i := 0; i < min(s.parallelism, s.games); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				result, gameErr := s.PlayGame(ctx, seed)
+				mu.Lock()
+				if gameErr != nil {
+					err = multierror.Append(err, fmt.Errorf("game %v: %w", seed, gameErr))
+				} else {
+					results[seed] = result
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{
+		TeamWins: make(map[roles.Team]int),
+		SoloWins: make(map[string]int),
+	}
+	for i := 0; i < s.games; i++ {
+		if result, ok := results[s.seed+int64(i)]; ok {
+			report.add(result)
+		}
+	}
+	if ctx.Err() != nil {
+		err = multierror.Append(err, ctx.Err())
+	}
+	return report, err
+}
+
+// PlayGame plays one game with the seed.
+func (s *Simulator) PlayGame(ctx context.Context, seed int64) (Result, error) {
+	gameCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	clock := timePack.NewFakeClock(time.Unix(0, 0))
+	discard := log.New(io.Discard, "", 0)
+	opts := append(append([]game.Option{}, s.gameOpts...),
+		game.ClockOpt(clock),
+		game.RandSeedOpt(seed),
+		game.InfoLoggerOpt(discard),
+		game.ErrLoggerOpt(discard),
+	)
+	g := game.GetNewGame(gameCtx, "simulation:"+strconv.FormatInt(seed, 10), opts...)
+
+	if err := g.SetMainChannel(&memoryChannel{serverID: "main"}); err != nil {
+		return Result{}, err
+	}
+	for _, role := range s.cfg.GetOrderToVote() {
+		if err := g.SetNewRoleChannel(newMemoryRoleChannel(role)); err != nil {
+			return Result{}, err
+		}
+	}
+	bots := make(player.NonPlayingPlayers, 0, s.cfg.PlayersCount)
+	for i := 1; i <= s.cfg.PlayersCount; i++ {
+		name := "bot:" + strconv.Itoa(i)
+		bots = append(bots, player.NewNonPlayingPlayer(name, name, name))
+	}
+	g.SetStartPlayers(&bots)
+	if err := g.Init(s.cfg); err != nil {
+		return Result{}, err
+	}
+
+	errCh, infoCh := g.Run(gameCtx)
+	driverDone := make(chan struct{})
+	defer close(driverDone)
+	go func() {
+		for {
+			select {
+			case _, ok := <-errCh:
+				if !ok {
+					return
+				}
+			case <-driverDone:
+				return
+			}
+		}
+	}()
+
+	d := &driver{
+		s:      s,
+		g:      g,
+		clock:  clock,
+		rand:   rand.New(rand.NewSource(seed)),
+		cancel: cancel,
+	}
+	d.drive(infoCh)
+	return s.result(g, seed)
+}
+
+func (s *Simulator) result(g *game.Game, seed int64) (Result, error) {
+	events := g.GetEvents()
+	if len(events) == 0 || events[len(events)-1].EventType != game.GameFinishedEvent {
+		return Result{}, GameIsNotPlayed
+	}
+	data, ok := events[len(events)-1].Data.(game.GameFinishedData)
+	if !ok {
+		return Result{}, GameIsNotPlayed
+	}
+	result := Result{
+		Seed:     seed,
+		Nights:   g.GetNightsCount(),
+		Duration: data.EndTime.Sub(g.GetStartTime()),
+	}
+	if data.Log != nil {
+		result.IsFinished = true
+		result.WinnerTeam = data.Log.WinnerTeam
+		result.SoloWinner = data.Log.SoloWinner
+	}
+	return result, nil
+}
@@ -0,0 +1,47 @@
+package time
+
+import "time"
+
+// Clock presents the source of the time for the game.
+//
+// The game uses RealClock by default. Use FakeClock to drive the game
+// deterministically (in tests, for example).
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// Timer same as time.Timer, but interfaced.
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing.
+	// Returns false if the timer has already expired or been stopped.
+	Stop() bool
+	// Reset changes the timer to expire after duration d.
+	// Returns true if the timer had been active.
+	Reset(d time.Duration) bool
+}
+
+// ____________
+// RealClock
+// ____________
+
+// RealClock is a Clock implementation, based on the time package.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
@@ -10,6 +10,14 @@ const (
 	FakeVotingMaxSeconds = 36
 
 	LastWordDeadline = 60
+
+	// Used only by the nomination day procedure.
+	NominationDeadline  = 60
+	DefenseDeadline     = 60
+	FinalVotingDeadline = 30
+
+	// Countdown of the lobby after all players are ready.
+	LobbyCountdown = 30
 )
 
 // Everything below is automatically calculated
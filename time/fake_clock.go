@@ -0,0 +1,204 @@
+package time
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a manual Clock implementation.
+//
+// The time stands still until Advance or AdvanceToNext is called,
+// so the game waits for you, not for the real deadlines.
+// Use BlockUntil to find out that the game is waiting for the timer.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	// changed is closed and replaced every time the set of waiting timers changes.
+	changed chan struct{}
+}
+
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{
+		now:     start,
+		changed: make(chan struct{}),
+	}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{
+		clock: c,
+		c:     make(chan time.Time, 1),
+	}
+	c.startTimer(t, d)
+	return t
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Advance moves the time forward, firing all timers whose deadline has come.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// AdvanceToNext moves the time to the nearest timer deadline and fires it.
+//
+// Returns the duration by which the time has been moved, and false if there are no waiting timers.
+func (c *FakeClock) AdvanceToNext() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.timers) == 0 {
+		return 0, false
+	}
+	next := c.timers[0].deadline
+	for _, t := range c.timers[1:] {
+		if t.deadline.Before(next) {
+			next = t.deadline
+		}
+	}
+	d := next.Sub(c.now)
+	c.now = next
+	c.fire()
+	return d, true
+}
+
+// Waiters presents the number of the timers (and sleepers) that are waiting right now.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are waiting.
+func (c *FakeClock) BlockUntil(n int) {
+	_ = c.BlockUntilContext(context.Background(), n)
+}
+
+// BlockUntilContext same as BlockUntil, but returns ctx.Err() if ctx is done first.
+func (c *FakeClock) BlockUntilContext(ctx context.Context, n int) error {
+	for {
+		c.mu.Lock()
+		if len(c.timers) >= n {
+			c.mu.Unlock()
+			return nil
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Internal, all below used under c.mu
+
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *FakeClock) startTimer(t *fakeTimer, d time.Duration) {
+	t.deadline = c.now.Add(d)
+	if d <= 0 {
+		t.send(c.now)
+		return
+	}
+	t.active = true
+	c.timers = append(c.timers, t)
+	c.notify()
+}
+
+func (c *FakeClock) removeTimer(t *fakeTimer) bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	for i, waiting := range c.timers {
+		if waiting == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			break
+		}
+	}
+	c.notify()
+	return true
+}
+
+func (c *FakeClock) fire() {
+	var expired, waiting []*fakeTimer
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			waiting = append(waiting, t)
+			continue
+		}
+		expired = append(expired, t)
+	}
+	if len(expired) == 0 {
+		return
+	}
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].deadline.Before(expired[j].deadline)
+	})
+	c.timers = waiting
+	for _, t := range expired {
+		t.active = false
+		t.send(c.now)
+	}
+	c.notify()
+}
+
+// ____________
+// fakeTimer
+// ____________
+
+type fakeTimer struct {
+	clock    *FakeClock
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.removeTimer(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := t.clock.removeTimer(t)
+	t.clock.startTimer(t, d)
+	return wasActive
+}
+
+// send is a non-blocking send, same as in time.Timer.
+func (t *fakeTimer) send(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
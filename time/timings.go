@@ -0,0 +1,145 @@
+package time
+
+import (
+	"math"
+	"time"
+)
+
+// Timings presents all durations of the game.
+//
+// Zero fields are replaced by default values (see DefaultTimings) in WithDefaults,
+// so you can specify only what you need.
+type Timings struct {
+	// VotingDeadline presents the night vote deadline of the role, if the role
+	// is not specified in RolesVotingDeadlines.
+	VotingDeadline time.Duration
+	// RolesVotingDeadlines presents the night vote deadline by role name.
+	RolesVotingDeadlines map[string]time.Duration
+	// RoleInfoPause presents the pause after the start of the game, used for
+	// participants to familiarize themselves with their roles.
+	RoleInfoPause time.Duration
+	// FakeVotingMin and FakeVotingMax presents the range of the fake vote duration
+	// (used, if all players with the role are muted).
+	FakeVotingMin time.Duration
+	FakeVotingMax time.Duration
+	// LastWordDeadline presents the time given to the dead players to say their last words.
+	LastWordDeadline time.Duration
+	// DayDeadline calculate the day max time.
+	DayDeadline DayDeadlineFormula
+
+	// NominationDeadline, DefenseDeadline and FinalVotingDeadline presents the
+	// durations of the nomination day procedure: nominations, defense speech of
+	// each nominee and the final (or runoff) vote.
+	NominationDeadline  time.Duration
+	DefenseDeadline     time.Duration
+	FinalVotingDeadline time.Duration
+	// LobbyCountdown presents the time between the moment, when all players of the lobby are ready,
+	// and the start of the game.
+	LobbyCountdown time.Duration
+}
+
+// DayDeadlineFormula calculate the day max time.
+type DayDeadlineFormula func(nightCounter int, deadCount int, totalPlayers int) time.Duration
+
+func DefaultTimings() Timings {
+	return Timings{
+		VotingDeadline:       VotingDeadline * time.Second,
+		RolesVotingDeadlines: make(map[string]time.Duration),
+		RoleInfoPause:        RoleInfoCount * time.Second,
+		FakeVotingMin:        FakeVotingMinSeconds * time.Second,
+		FakeVotingMax:        FakeVotingMaxSeconds * time.Second,
+		LastWordDeadline:     LastWordDeadline * time.Second,
+		DayDeadline:          CalculateDayDeadline,
+		NominationDeadline:   NominationDeadline * time.Second,
+		DefenseDeadline:      DefenseDeadline * time.Second,
+		FinalVotingDeadline:  FinalVotingDeadline * time.Second,
+		LobbyCountdown:       LobbyCountdown * time.Second,
+	}
+}
+
+// WithDefaults returns Timings, where all zero fields are replaced by DefaultTimings.
+func (t Timings) WithDefaults() Timings {
+	defaults := DefaultTimings()
+	if t.VotingDeadline <= 0 {
+		t.VotingDeadline = defaults.VotingDeadline
+	}
+	if t.RolesVotingDeadlines == nil {
+		t.RolesVotingDeadlines = defaults.RolesVotingDeadlines
+	}
+	if t.RoleInfoPause <= 0 {
+		t.RoleInfoPause = defaults.RoleInfoPause
+	}
+	if t.FakeVotingMin <= 0 {
+		t.FakeVotingMin = defaults.FakeVotingMin
+	}
+	if t.FakeVotingMax <= 0 {
+		t.FakeVotingMax = max(defaults.FakeVotingMax, t.FakeVotingMin)
+	}
+	if t.FakeVotingMax < t.FakeVotingMin {
+		t.FakeVotingMin, t.FakeVotingMax = t.FakeVotingMax, t.FakeVotingMin
+	}
+	if t.LastWordDeadline <= 0 {
+		t.LastWordDeadline = defaults.LastWordDeadline
+	}
+	if t.DayDeadline == nil {
+		t.DayDeadline = defaults.DayDeadline
+	}
+	if t.NominationDeadline <= 0 {
+		t.NominationDeadline = defaults.NominationDeadline
+	}
+	if t.DefenseDeadline <= 0 {
+		t.DefenseDeadline = defaults.DefenseDeadline
+	}
+	if t.FinalVotingDeadline <= 0 {
+		t.FinalVotingDeadline = defaults.FinalVotingDeadline
+	}
+	if t.LobbyCountdown <= 0 {
+		t.LobbyCountdown = defaults.LobbyCountdown
+	}
+	return t
+}
+
+// GetVotingDeadline returns the night vote deadline of the role.
+func (t Timings) GetVotingDeadline(roleName string) time.Duration {
+	if deadline, ok := t.RolesVotingDeadlines[roleName]; ok && deadline > 0 {
+		return deadline
+	}
+	return t.VotingDeadline
+}
+
+// ____________________
+// Day deadline
+// ____________________
+
+// Weights of aspects, used in CalculateDayDeadline
+const (
+	DayDeadlineBasicMinutes            = 0.0
+	DayDeadlineNightCounterWeight      = 0.61
+	DayDeadlineDeadCountWeight         = 0.68
+	DayDeadlineTotalPlayersCountWeight = 0.27
+)
+
+// CalculateDayDeadline calculate the day max time with default weights.
+func CalculateDayDeadline(nightCounter int, deadCount int, totalPlayers int) time.Duration {
+	return DayDeadlineByWeights(
+		DayDeadlineBasicMinutes,
+		DayDeadlineNightCounterWeight,
+		DayDeadlineDeadCountWeight,
+		DayDeadlineTotalPlayersCountWeight,
+	)(nightCounter, deadCount, totalPlayers)
+}
+
+// DayDeadlineByWeights returns the formula, where every aspect of the game adds
+// minutes with its weight to basicMinutes. The result is rounded up to minutes.
+func DayDeadlineByWeights(basicMinutes, nightCounterWeight, deadCountWeight,
+	totalPlayersCountWeight float64) DayDeadlineFormula {
+	return func(nightCounter int, deadCount int, totalPlayers int) time.Duration {
+		nightCounterAddMinutes := nightCounterWeight * float64(nightCounter)
+		deadCountAddMinutes := deadCountWeight * float64(deadCount)
+		totalPlayersCountAddMinutes := totalPlayersCountWeight * float64(totalPlayers)
+
+		totalTime := basicMinutes + nightCounterAddMinutes + deadCountAddMinutes + totalPlayersCountAddMinutes
+		totalTimeMinutes := math.Ceil(totalTime)
+		return time.Minute * time.Duration(totalTimeMinutes)
+	}
+}